@@ -21,15 +21,20 @@ var (
 // must contain actual text and whitespace-only html.TextNodes don't
 // result in Chunks.
 type Chunk struct {
-	Prev      *Chunk     // previous chunk
-	Next      *Chunk     // next chunk
-	Text      *util.Text // text of this chunk
-	Base      *html.Node // element node which contained this chunk
-	Block     *html.Node // parent block node of base node
-	Container *html.Node // parent block node of block node
-	Classes   []string   // list of classes this chunk belongs to
-	Ancestors int        // bitmask of the ancestors of this chunk
-	LinkText  float32    // link text to normal text ratio.
+	Prev         *Chunk     // previous chunk
+	Next         *Chunk     // next chunk
+	Text         *util.Text // text of this chunk
+	Base         *html.Node // element node which contained this chunk
+	Block        *html.Node // parent block node of base node
+	Container    *html.Node // parent block node of block node
+	Classes      []string   // list of classes this chunk belongs to
+	HeadingLevel int        // level of headings, e.g. 2 for <h2>, or 0
+	Ancestors    int        // bitmask of the Ancestor* types enclosing this chunk
+	Preformatted bool       // text of a <pre> element with whitespace preserved
+	LinkText     float32    // link text to normal text ratio.
+	URL          string     // target of the link if the chunk is a link
+	Offset       int        // byte offset of the text in the HTML data or -1
+	Length       int        // byte length of the text in the HTML data
 }
 
 // The list of inline elements was taken from:
@@ -71,6 +76,16 @@ var inlineElement = map[atom.Atom]bool{
 	atom.Var:      true,
 }
 
+// headingLevels maps the heading elements to their levels.
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1,
+	atom.H2: 2,
+	atom.H3: 3,
+	atom.H4: 4,
+	atom.H5: 5,
+	atom.H6: 6,
+}
+
 func getParentBlock(n *html.Node) *html.Node {
 	// Keep ascending as long as the node points to an HTML inline element.
 	for n != nil && n.Parent != nil && inlineElement[n.DataAtom] {
@@ -98,8 +113,21 @@ func NewChunk(doc *Document, n *html.Node) (*Chunk, error) {
 		chunk.Base = n.Parent
 	}
 
-	// Write the text of all TextNodes of n to chunk.Text.
-	iterateText(n, chunk.Text.WriteString)
+	// Write the text of all TextNodes of n to chunk.Text. The whitespace of
+	// preformatted text is significant, so it's kept as it is, apart from
+	// surrounding blank lines.
+	if n.Type == html.ElementNode && n.DataAtom == atom.Pre && doc.parser.KeepPreformatted {
+		var buf strings.Builder
+		IterateText(n, func(s string) {
+			buf.WriteString(s)
+		})
+		if text := strings.Trim(buf.String(), "\r\n"); strings.TrimSpace(text) != "" {
+			chunk.Text.WritePreformatted(text)
+			chunk.Preformatted = true
+		}
+	} else {
+		IterateText(n, chunk.Text.WriteString)
+	}
 
 	// Don't produce Chunks without text.
 	if chunk.Text.Len() == 0 {
@@ -143,9 +171,28 @@ func NewChunk(doc *Document, n *html.Node) (*Chunk, error) {
 		chunk.Container = chunk.Block
 	}
 
+	// Remember the level of headings.
+	chunk.HeadingLevel = headingLevels[chunk.Block.DataAtom]
+
 	// Remember the ancestors in our chunk.
 	chunk.Ancestors = doc.ancestors
 
+	// Remember the position of the chunk's text in the HTML data, which
+	// includes the markup between the first and last text node. The
+	// positions refer to the data after decoding it to UTF-8.
+	chunk.Offset = -1
+	if s, ok := doc.findChunkSpan(n); ok {
+		chunk.Offset = s.start
+		chunk.Length = s.end - s.start
+	}
+
+	// Remember the target of links.
+	if chunk.Base.DataAtom == atom.A {
+		if href := getAttribute(chunk.Base, "href"); href != "" {
+			chunk.URL, _ = doc.resolveLink("", href)
+		}
+	}
+
 	// Calculate the ratio between text inside links and text outside links
 	// for the current element's block node. This is useful to determine the
 	// quality of a link. Links used as cross references inside the doc
@@ -158,13 +205,7 @@ func NewChunk(doc *Document, n *html.Node) (*Chunk, error) {
 	//
 	//   <li><a>See also: ...</a></li>
 	//
-	linkText := doc.linkText[chunk.Block]
-	normText := doc.normText[chunk.Block]
-	if normText == 0 && linkText == 0 {
-		chunk.LinkText = 0.0
-	} else {
-		chunk.LinkText = float32(linkText) / float32(linkText+normText)
-	}
+	chunk.LinkText = doc.linkDensity(chunk.Block)
 
 	// Detect the classes of the current node. We use the good old class
 	// attribute and the new HTML5 microdata (itemprop attribute) to determine
@@ -231,10 +272,5 @@ func (ch *Chunk) GetChildTypes() []string {
 }
 
 func (ch *Chunk) IsHeading() bool {
-	switch ch.Block.DataAtom {
-	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
-		return true
-	default:
-		return false
-	}
+	return ch.HeadingLevel > 0
 }
@@ -1,54 +1,325 @@
 package html
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/slyrz/newscat/util"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
 	"io"
-	"unicode"
+	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
 // Errors returned during Document parsing.
 var (
 	ErrNoHTML = errors.New("missing html element")
-	ErrNoHead = errors.New("missing head element")
+	ErrNoHead = errors.New("missing head element") // unused, kept for compatibility
 	ErrNoBody = errors.New("missing body element")
+	ErrParse  = errors.New("malformed html")
+
+	ErrCharset = errors.New("unknown charset")
 )
 
 // Document is a parsed HTML document that extracts the document title and
 // holds unexported pointers to the html, head and body nodes.
 type Document struct {
+	URL    string     // the URL the document was fetched from, if any.
 	Title  *util.Text // the <title>...</title> text.
 	Chunks []*Chunk   // all chunks found in this document.
+	Images []*Image   // all images found in this document.
+	Embeds []Embed    // all videos embedded in this document.
+
+	// Truncated is true if elements were nested deeper than the parser's
+	// MaxDepth. Their contents are missing in the document.
+	Truncated bool
 
 	// Unexported fields.
 	html *html.Node // the <html>...</html> part
 	head *html.Node // the <head>...</head> part
 	body *html.Node // the <body>...</body> part
 
+	jsonLD     []map[string]interface{} // JSON-LD objects found in the document
+	baseHref   string                   // href of the <base> element
+	baseTarget string                   // target of the <base> element
+	nextHref   string                   // href of the next page's link
+	feeds      []util.Link              // feed links with unresolved URLs
+	crumbs     []string                 // link texts of the breadcrumb navigation
+
 	// State variables used during parsing.
-	ancestors int                // bitmask to track specific ancestor types
-	linkText  map[*html.Node]int // length of text inside <a></a> tags
-	normText  map[*html.Node]int // length of text outside <a></a> tags
+	parser    *Parser                  // settings of the parsing process
+	fragment  bool                     // data is a fragment of the body
+	ctx       context.Context          // context of the parsing process
+	offsets   map[*html.Node]span      // positions of text nodes if tracked
+	err       error                    // error of ctx, once it's done
+	visited   int                      // number of visited nodes
+	ancestors int                      // bitmask to track specific ancestor types
+	textCount map[*html.Node]textCount // text lengths of possible block nodes
 }
 
 // NewDocument parses the HTML data provided through an io.Reader interface.
+// The character encoding of the data is detected from byte order marks and
+// <meta> elements. Data without encoding declaration is treated as UTF-8 if
+// it's valid UTF-8 and as windows-1252 otherwise.
 func NewDocument(r io.Reader) (*Document, error) {
-	root, err := html.Parse(r)
+	return NewParser().Parse(r)
+}
+
+// NewDocumentFromBytes parses the HTML data stored in data.
+func NewDocumentFromBytes(data []byte) (*Document, error) {
+	return NewDocument(bytes.NewReader(data))
+}
+
+// NewDocumentFromString parses the HTML data stored in s.
+func NewDocumentFromString(s string) (*Document, error) {
+	return NewDocument(strings.NewReader(s))
+}
+
+// NewDocumentWithCharset parses the HTML data provided through an io.Reader
+// interface, which is encoded using the given charset, e.g. "iso-8859-1".
+// The charset overrides any encoding declared by the data. If charset is
+// empty, the encoding gets detected like in NewDocument.
+func NewDocumentWithCharset(r io.Reader, charset string) (*Document, error) {
+	parser := NewParser()
+	parser.Charset = charset
+	return parser.Parse(r)
+}
+
+// NewDocumentStreaming works like NewDocument, but drops scripts, styles
+// and comments while reading the data to reduce the memory needed for
+// large pages. See Parser.Streaming.
+func NewDocumentStreaming(r io.Reader) (*Document, error) {
+	parser := NewParser()
+	parser.Streaming = true
+	return parser.Parse(r)
+}
+
+// NewDocumentContext works like NewDocument, but stops parsing once ctx is
+// done. In this case it returns the error of ctx.
+func NewDocumentContext(ctx context.Context, r io.Reader) (*Document, error) {
+	return NewParser().ParseContext(ctx, r)
+}
+
+// NewDocumentFragment parses a fragment of HTML data, e.g. a stored article
+// body, as content of a <body> element. The Document has an empty head.
+func NewDocumentFragment(r io.Reader) (*Document, error) {
+	return NewParser().ParseFragment(r)
+}
+
+// NewDocumentFromURL fetches the HTML page at url using a util.Client with
+// default settings and parses it.
+func NewDocumentFromURL(url string) (*Document, error) {
+	return NewParser().ParseURL(util.NewClient(util.DefaultTimeout, util.DefaultUserAgent), url)
+}
+
+// Head returns the <head> element of the document. Documents without head
+// get an empty one.
+func (doc *Document) Head() *html.Node {
+	return doc.head
+}
+
+// Body returns the <body> element of the document. Elements removed before
+// parsing the body are missing.
+func (doc *Document) Body() *html.Node {
+	return doc.body
+}
+
+// Reset parses the HTML data provided through an io.Reader interface into
+// doc, replacing its previous content, using the same settings as before.
+// It reuses the memory of doc's chunk and image lists and internal maps,
+// which reduces allocations when parsing many documents in a row. Chunks
+// and Images of the previous content must not be used afterwards.
+func (doc *Document) Reset(r io.Reader) error {
+	parser := doc.parser
+	if parser == nil {
+		parser = NewParser()
+	}
+	// Clear the old pointers, so the garbage collector can free the old
+	// chunks and images even if the new document has fewer of them.
+	for i := range doc.Chunks {
+		doc.Chunks[i] = nil
+	}
+	for i := range doc.Images {
+		doc.Images[i] = nil
+	}
+	for n := range doc.textCount {
+		delete(doc.textCount, n)
+	}
+	*doc = Document{
+		Chunks:    doc.Chunks[:0],
+		Images:    doc.Images[:0],
+		textCount: doc.textCount,
+		fragment:  doc.fragment,
+	}
+	return doc.init(context.Background(), r, parser)
+}
+
+// contextReader is an io.Reader that fails once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// newDecoder returns a reader converting the data of r from the charset
+// label to UTF-8. If label is empty, the charset gets detected from the
+// first 1024 bytes of data. Data without byte order mark, charset
+// declaration and non-ASCII bytes in the first 1024 bytes is assumed to be
+// UTF-8.
+func newDecoder(r io.Reader, label string) (io.Reader, error) {
+	var enc encoding.Encoding
+
+	src := bufio.NewReaderSize(r, 1024)
+	if label != "" {
+		if enc, _ = charset.Lookup(label); enc == nil {
+			return nil, ErrCharset
+		}
+	} else {
+		// Peek fails if there are less than 1024 bytes, but returns the
+		// bytes read so far, which is all we need.
+		preview, _ := src.Peek(1024)
+		var certain bool
+		enc, _, certain = charset.DetermineEncoding(preview, "")
+		// DetermineEncoding falls back to windows-1252 if it finds no
+		// evidence, but most pages with an ASCII-only start are UTF-8.
+		if !certain && enc == charmap.Windows1252 && isASCII(preview) && !declaresCharset(preview) {
+			enc = encoding.Nop
+		}
+	}
+
+	// The decoders preserve byte order marks, which would end up as text
+	// in the document body.
+	dst := bufio.NewReader(transform.NewReader(src, enc.NewDecoder()))
+	if r, _, err := dst.ReadRune(); err == nil && r != '\ufeff' {
+		dst.UnreadRune()
+	}
+	return dst, nil
+}
+
+// isASCII returns true if data contains no bytes above 0x7f.
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// declaresCharset returns true if data contains a <meta> element declaring
+// the charset of the document.
+func declaresCharset(data []byte) bool {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			if token.DataAtom != atom.Meta {
+				continue
+			}
+			for _, attr := range token.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "charset":
+					return true
+				case "content":
+					if util.GetCharset(attr.Val) != "" {
+						return true
+					}
+				}
+			}
+		}
+	}
+}
+
+// parseError wraps err, which occurred while reading or parsing the HTML
+// data, so that it matches ErrParse as well. Errors caused by ctx are
+// returned as is.
+func parseError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrParse, err)
+}
+
+// parse parses the HTML data of r into a node tree. Fragments are parsed as
+// content of a <body> element, which gets wrapped in a synthesized document.
+func (doc *Document) parse(r io.Reader) (*html.Node, error) {
+	if !doc.fragment {
+		return html.Parse(r)
+	}
+	body := &html.Node{Type: html.ElementNode, DataAtom: atom.Body, Data: "body"}
+	nodes, err := html.ParseFragment(r, body)
 	if err != nil {
 		return nil, err
 	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	root := &html.Node{Type: html.ElementNode, DataAtom: atom.Html, Data: "html"}
+	root.AppendChild(&html.Node{Type: html.ElementNode, DataAtom: atom.Head, Data: "head"})
+	root.AppendChild(body)
+	result := &html.Node{Type: html.DocumentNode}
+	result.AppendChild(root)
+	return result, nil
+}
+
+// init parses the HTML data of r using the settings of parser.
+func (doc *Document) init(ctx context.Context, r io.Reader, parser *Parser) error {
+	doc.ctx = ctx
+	doc.parser = parser
+	r, err := newDecoder(&contextReader{ctx, r}, parser.Charset)
+	if err != nil {
+		return err
+	}
+
+	var root *html.Node
+	if parser.TrackOffsets {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return parseError(ctx, err)
+		}
+		if root, err = doc.parse(bytes.NewReader(data)); err != nil {
+			return parseError(ctx, err)
+		}
+		doc.Truncated = pruneDepth(root, parser.MaxDepth)
+		doc.offsets = findTextOffsets(data, root)
+	} else {
+		if parser.Streaming {
+			r = newStreamFilter(r, parser.removes)
+		}
+		if root, err = doc.parse(r); err != nil {
+			return parseError(ctx, err)
+		}
+		doc.Truncated = pruneDepth(root, parser.MaxDepth)
+	}
 
-	doc := &Document{
-		Title:    util.NewText(),
-		Chunks:   make([]*Chunk, 0, 512),
-		linkText: make(map[*html.Node]int),
-		normText: make(map[*html.Node]int),
+	// Reset passes the slices of the previous document for reuse.
+	doc.Title = util.NewText()
+	if doc.Chunks == nil {
+		doc.Chunks = make([]*Chunk, 0, 512)
+	}
+	if doc.Images == nil {
+		doc.Images = make([]*Image, 0, 16)
 	}
 
 	// Assign the fields html, head and body from the HTML page.
-	iterateNode(root, func(n *html.Node) int {
+	IterateNode(root, func(n *html.Node) int {
 		switch n.DataAtom {
 		case atom.Html:
 			doc.html = n
@@ -64,21 +335,40 @@ func NewDocument(r io.Reader) (*Document, error) {
 		return IterNext
 	})
 
+	// The parser synthesizes missing <html>, <head> and <body> elements,
+	// but pages made of frames still lack a body. Without a body, there's
+	// no content to extract. A missing head only means a lack of metadata,
+	// so use an empty one.
+	if doc.head == nil {
+		doc.head = &html.Node{Type: html.ElementNode, DataAtom: atom.Head, Data: "head"}
+	}
 	switch {
 	case doc.html == nil:
-		return nil, ErrNoHTML
-	case doc.head == nil:
-		return nil, ErrNoHead
+		return ErrNoHTML
 	case doc.body == nil:
-		return nil, ErrNoBody
+		return ErrNoBody
 	}
 
+	// Only the first <base> element with href attribute counts, the same
+	// goes for the target attribute.
+	IterateNode(doc.head, func(n *html.Node) int {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Base {
+			if href := strings.TrimSpace(getAttribute(n, "href")); href != "" && doc.baseHref == "" {
+				doc.baseHref = href
+			}
+			if target := strings.TrimSpace(getAttribute(n, "target")); target != "" && doc.baseTarget == "" {
+				doc.baseTarget = target
+			}
+		}
+		return IterNext
+	})
+
 	// Detect the document title: First check if the document provides
 	// Open Graph metadata; if so, use the metadata rather than the
 	// value of the title element, because the metadata tends to be a tad
 	// cleaner.
 	title := ""
-	iterateNode(doc.head, func(n *html.Node) int {
+	IterateNode(doc.head, func(n *html.Node) int {
 		if n.Type == html.ElementNode && n.DataAtom == atom.Meta {
 			prop, content := "", ""
 			for _, attr := range n.Attr {
@@ -99,18 +389,35 @@ func NewDocument(r io.Reader) (*Document, error) {
 	if title != "" {
 		doc.Title.WriteString(title)
 	} else {
-		iterateNode(doc.head, func(n *html.Node) int {
+		IterateNode(doc.head, func(n *html.Node) int {
 			if n.Type == html.ElementNode && n.DataAtom == atom.Title {
-				iterateText(n, doc.Title.WriteString)
+				IterateText(n, doc.Title.WriteString)
 				return IterStop
 			}
 			return IterNext
 		})
 	}
 
+	// Cleaning removes the <script> elements, so we have to read the JSON-LD
+	// data first. The same goes for pagination and breadcrumbs inside of
+	// <nav> elements, feed links in footers and videos in <iframe> elements.
+	doc.jsonLD = parseJSONLD(doc.html)
+	doc.nextHref = findNextPage(doc.html)
+	doc.feeds = findFeeds(doc.html)
+	doc.crumbs = findBreadcrumbs(doc.html)
+	doc.Embeds = findEmbeds(doc.body)
+
 	doc.cleanBody(doc.body, 0)
-	doc.countText(doc.body, false)
+	if !parser.SkipLinkText {
+		if doc.textCount == nil {
+			doc.textCount = make(map[*html.Node]textCount)
+		}
+		doc.countText(doc.body, false)
+	}
 	doc.parseBody(doc.body)
+	if doc.err != nil {
+		return doc.err
+	}
 
 	// Now we link the chunks.
 	min, max := 0, len(doc.Chunks)-1
@@ -122,7 +429,49 @@ func NewDocument(r io.Reader) (*Document, error) {
 			doc.Chunks[i].Next = doc.Chunks[i+1]
 		}
 	}
-	return doc, nil
+	return nil
+}
+
+// pruneDepth removes the children of nodes nested maxDepth levels below
+// root, so the recursive traversals of the tree can't exhaust the stack. It
+// walks the tree without recursion and returns true if it removed nodes.
+// A maxDepth of zero or less removes nothing.
+func pruneDepth(root *html.Node, maxDepth int) bool {
+	if maxDepth <= 0 {
+		return false
+	}
+	pruned := false
+	for n, depth := root, 0; n != nil; {
+		if n.FirstChild != nil && depth < maxDepth {
+			n, depth = n.FirstChild, depth+1
+			continue
+		}
+		for n.FirstChild != nil {
+			n.RemoveChild(n.FirstChild)
+			pruned = true
+		}
+		// Ascend until there's a sibling to continue with.
+		for n != root && n.NextSibling == nil {
+			n, depth = n.Parent, depth-1
+		}
+		if n == root {
+			break
+		}
+		n = n.NextSibling
+	}
+	return pruned
+}
+
+// canceled returns true if the document's context is done. Checking the
+// context on every node would be wasteful, so canceled checks it only
+// every few calls. Once canceled, the body traversals stop descending.
+func (doc *Document) canceled() bool {
+	const checkInterval = 256
+
+	if doc.visited++; doc.err == nil && doc.visited%checkInterval == 0 {
+		doc.err = doc.ctx.Err()
+	}
+	return doc.err != nil
 }
 
 const (
@@ -132,14 +481,26 @@ const (
 	AncestorAside
 	AncestorBlockquote
 	AncestorList
+	AncestorMain
+	AncestorSection
 )
 
+// textCount is the length of the text inside and outside of links.
+type textCount struct {
+	linkText int // length of text inside <a></a> tags
+	normText int // length of text outside <a></a> tags
+}
+
 // countText counts the text inside of links and the text outside of links
 // per html.Node. Counting is done cumulative, so the numbers of a parent node
-// include the numbers of its child nodes.
+// include the numbers of its child nodes. Only the numbers of block-level
+// elements are stored, because only they can become a chunk's block.
 func (doc *Document) countText(n *html.Node, insideLink bool) (linkText int, normText int) {
 	linkText = 0
 	normText = 0
+	if doc.canceled() {
+		return
+	}
 	if n.Type == html.ElementNode && n.DataAtom == atom.A {
 		insideLink = true
 	}
@@ -149,23 +510,53 @@ func (doc *Document) countText(n *html.Node, insideLink bool) (linkText int, nor
 		normText += normTextChild
 	}
 	if n.Type == html.TextNode {
-		count := 0
-		for _, rune := range n.Data {
-			if unicode.IsLetter(rune) {
-				count += 1
-			}
-		}
+		count := util.CountLetters(n.Data)
 		if insideLink {
 			linkText += count
 		} else {
 			normText += count
 		}
 	}
-	doc.linkText[n] = linkText
-	doc.normText[n] = normText
+	if n.Type == html.ElementNode && !inlineElement[n.DataAtom] {
+		doc.textCount[n] = textCount{linkText, normText}
+	}
 	return
 }
 
+// linkDensity returns the ratio of letters inside links to all letters of
+// the node n or zero if n contains no letters.
+func (doc *Document) linkDensity(n *html.Node) float32 {
+	count := doc.textCount[n]
+	if count.normText == 0 && count.linkText == 0 {
+		return 0.0
+	}
+	return float32(count.linkText) / float32(count.linkText+count.normText)
+}
+
+// LinkDensity returns the ratio of letters inside links to all letters of
+// the chunk's block. It's close to 1 for navigation and close to 0 for
+// content. If the block contains no letters or the Parser skipped counting
+// them, LinkDensity returns 0.
+func (doc *Document) LinkDensity(chunk *Chunk) float32 {
+	return doc.linkDensity(chunk.Block)
+}
+
+// Letters returns the number of letters of the body's text, including the
+// text of ignored elements, but not of removed ones. If the Parser skipped
+// counting the link text, only the letters of the chunks are counted.
+func (doc *Document) Letters() int {
+	if count, ok := doc.textCount[doc.body]; ok {
+		return count.linkText + count.normText
+	}
+	result := 0
+	for _, chunk := range doc.Chunks {
+		result += chunk.Text.LetterCount()
+	}
+	return result
+}
+
+// removeElements are the elements removed before parsing the body by
+// default. See Parser.Strip and Parser.Keep.
 var removeElements = map[atom.Atom]bool{
 	atom.Address:    true,
 	atom.Audio:      true,
@@ -195,9 +586,19 @@ var removeElements = map[atom.Atom]bool{
 
 // cleanBody removes unwanted HTML elements from the HTML body.
 func (doc *Document) cleanBody(n *html.Node, level int) {
+	if doc.canceled() {
+		return
+	}
+
 	// removeNode returns true if a node should be removed from HTML document.
+	// Tables close to the body are most likely used for the page layout rather
+	// than for data, so they are removed if they're not nested deeper than the
+	// parser's LayoutTableLevel. Stripping tables removes all of them.
 	removeNode := func(c *html.Node, level int) bool {
-		return removeElements[c.DataAtom]
+		if doc.parser.removes(c.DataAtom) || doc.parser.excluded(c) {
+			return true
+		}
+		return c.DataAtom == atom.Table && level < doc.parser.LayoutTableLevel
 	}
 
 	var curr *html.Node = n.FirstChild
@@ -218,47 +619,37 @@ func (doc *Document) cleanBody(n *html.Node, level int) {
 }
 
 var (
-	ignoreNames = util.NewRegexFromWords(
-		"breadcrumb",
-		"byline",
-		"caption",
-		"comment",
-		"community",
-		"credit",
-		"description",
-		"email",
-		"footer",
-		"gallery",
-		"hide",
-		"infotext",
-		"photo",
-		"related",
-		"shares",
-		"social",
-		"story[-_]?bar",
-		"story[-_]?feature",
-	)
-	ignoreStyle = util.NewRegex(`(?i)display:\s*none`)
+	ignoreStyle = util.NewRegex(`(?i)display:\s*none|visibility:\s*hidden`)
 )
 
 // parseBody parses the <body>...</body> part of the HTML page. It creates
 // Chunks for every html.TextNode found in the body.
 func (doc *Document) parseBody(n *html.Node) {
+	if doc.canceled() {
+		return
+	}
 	switch n.Type {
 	case html.ElementNode:
 		// We ignore the node if it has some nasty classes/ids/itemprops or if
-		// its style attribute contains "display: none".
+		// it's hidden by its style attribute, the hidden attribute or
+		// aria-hidden="true".
 		if n.DataAtom != atom.Body && n.DataAtom != atom.Article {
 			for _, attr := range n.Attr {
 				switch attr.Key {
 				case "id", "class", "itemprop":
-					if ignoreNames.In(attr.Val) {
+					if doc.parser.ignoreNames != nil && doc.parser.ignoreNames.In(attr.Val) {
 						return
 					}
 				case "style":
 					if ignoreStyle.In(attr.Val) {
 						return
 					}
+				case "hidden":
+					return
+				case "aria-hidden":
+					if strings.EqualFold(strings.TrimSpace(attr.Val), "true") {
+						return
+					}
 				}
 			}
 		}
@@ -270,10 +661,37 @@ func (doc *Document) parseBody(n *html.Node) {
 		// Descending into these children and handling every TextNode separately
 		// would make things unnecessary complicated and our results noisy.
 		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.A:
+			// Links frequently wrap images, so we don't want to lose them.
+			IterateNode(n, func(c *html.Node) int {
+				if isImage(c) {
+					doc.addImage(c)
+				}
+				return IterNext
+			})
 			if chunk, err := NewChunk(doc, n); err == nil {
 				doc.Chunks = append(doc.Chunks, chunk)
 			}
 			return
+		// Preformatted text, like source code, becomes a single chunk, which
+		// keeps its whitespace.
+		case atom.Pre:
+			if doc.parser.KeepPreformatted {
+				if chunk, err := NewChunk(doc, n); err == nil {
+					doc.Chunks = append(doc.Chunks, chunk)
+				}
+				return
+			}
+		case atom.Noscript:
+			if doc.parser.KeepNoscript {
+				unwrapNoscript(n)
+			}
+		case atom.Img:
+			doc.addImage(n)
+		case 0:
+			// Custom elements have no atom.
+			if isImage(n) {
+				doc.addImage(n)
+			}
 		// Now mask the element type, but only if it isn't already set.
 		// If we mask a bit which was already set by one of our callers, we'd also
 		// clear it at the end of this function, though it actually should be cleared
@@ -286,6 +704,10 @@ func (doc *Document) parseBody(n *html.Node) {
 			ancestorMask = AncestorBlockquote &^ doc.ancestors
 		case atom.Ul, atom.Ol:
 			ancestorMask = AncestorList &^ doc.ancestors
+		case atom.Main:
+			ancestorMask = AncestorMain &^ doc.ancestors
+		case atom.Section:
+			ancestorMask = AncestorSection &^ doc.ancestors
 		}
 		// Add our mask to the ancestor bitmask.
 		doc.ancestors |= ancestorMask
@@ -301,6 +723,33 @@ func (doc *Document) parseBody(n *html.Node) {
 	}
 }
 
+// unwrapNoscript replaces the raw text the HTML parser keeps inside of the
+// <noscript> element n with the elements it contains. Parsing the whole page
+// with scripting disabled would do the same, but it also makes <noscript>
+// elements in the head end the head early, taking the title and metadata
+// along into the body.
+func unwrapNoscript(n *html.Node) {
+	c := n.FirstChild
+	if c == nil || c.Type != html.TextNode || c.NextSibling != nil || n.Parent == nil {
+		return
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(c.Data), n.Parent)
+	if err != nil {
+		return
+	}
+	n.RemoveChild(c)
+	for _, node := range nodes {
+		n.AppendChild(node)
+	}
+}
+
+// addImage adds the <img> or <amp-img> element n to the document's images.
+func (doc *Document) addImage(n *html.Node) {
+	if img := NewImage(doc, n); img != nil {
+		doc.Images = append(doc.Images, img)
+	}
+}
+
 // TextStat contains the number of words and sentences found in text.
 type TextStat struct {
 	Words     int // total number of words
@@ -326,6 +775,30 @@ func (doc *Document) GetClassStats() map[string]*TextStat {
 	return result
 }
 
+// ClassStat contains the TextStat of a class.
+type ClassStat struct {
+	Class string
+	TextStat
+}
+
+// GetClassStatsSorted works like GetClassStats, but returns the stats as
+// list sorted by number of words in descending order. Classes with the same
+// number of words are sorted by name, so the order is deterministic.
+func (doc *Document) GetClassStatsSorted() []ClassStat {
+	stats := doc.GetClassStats()
+	result := make([]ClassStat, 0, len(stats))
+	for class, stat := range stats {
+		result = append(result, ClassStat{class, *stat})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Words != result[j].Words {
+			return result[i].Words > result[j].Words
+		}
+		return result[i].Class < result[j].Class
+	})
+	return result
+}
+
 // GetClusterStats groups the document chunks by common ancestors and
 // calculates TextStats for each group of chunks.
 func (doc *Document) GetClusterStats() map[*Chunk]*TextStat {
@@ -0,0 +1,631 @@
+package html
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCharset(t *testing.T) {
+	const want = "Café à la crème"
+
+	// The page is encoded in ISO-8859-1, "é" is 0xe9, "à" is 0xe0 and
+	// "è" is 0xe8.
+	title := "<title>Caf\xe9 \xe0 la cr\xe8me</title></head>"
+	body := "<body><p>Caf\xe9 \xe0 la cr\xe8me</p></body></html>"
+	tests := []struct {
+		data    string
+		charset string
+	}{
+		{`<html><head><meta charset="iso-8859-1">` + title + body, ""},
+		{`<html><head><meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1">` + title + body, ""},
+		{`<html><head>` + title + body, ""},
+		{`<html><head><meta charset="utf-8">` + title + body, "latin1"},
+		{"\xef\xbb\xbf<html><head><title>Café à la crème</title></head><body><p>Café à la crème</p></body></html>", ""},
+	}
+	for _, test := range tests {
+		doc, err := NewDocumentWithCharset(strings.NewReader(test.data), test.charset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if title := doc.Title.String(); title != want {
+			t.Errorf("title %q, want %q", title, want)
+		}
+		if text := doc.Chunks[0].Text.String(); text != want {
+			t.Errorf("text %q, want %q", text, want)
+		}
+	}
+
+	if _, err := NewDocumentWithCharset(strings.NewReader(body), "no-such-charset"); err != ErrCharset {
+		t.Errorf("expected ErrCharset")
+	}
+}
+
+func TestCharsetASCIIPreview(t *testing.T) {
+	const want = "Café à la crème"
+
+	// Only the first 1024 bytes are used to detect the charset.
+	head := "<html><head><title>News</title><!--" + strings.Repeat(" ", 1024) + "--></head>"
+	tests := []struct {
+		data string
+		want string
+	}{
+		{head + "<body><p>Café à la crème</p></body></html>", want},
+		{"<p>" + strings.Repeat("Text. ", 200) + "</p><p>Café à la crème</p>", want},
+		// A declared charset is still used.
+		{`<html><head><meta charset="iso-8859-1">` + head[12:] + "<body><p>Caf\xe9 \xe0 la cr\xe8me</p></body></html>", want},
+	}
+	for i, test := range tests {
+		doc, err := NewDocumentFromString(test.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := doc.Chunks[len(doc.Chunks)-1].Text.String(); got != test.want {
+			t.Errorf("%d: text %q, want %q", i, got, test.want)
+		}
+	}
+
+	doc, err := NewDocumentFragment(strings.NewReader(strings.Repeat("<p>Text.</p>", 100) + "<p>Café à la crème</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Chunks[len(doc.Chunks)-1].Text.String(); got != want {
+		t.Errorf("fragment: text %q, want %q", got, want)
+	}
+}
+
+func TestLegacyCharsets(t *testing.T) {
+	tests := []struct {
+		charset string
+		data    string
+		want    string
+	}{
+		// "表" is 0x95 0x5c in Shift_JIS, its second byte is a backslash.
+		{"shift_jis", "\x95\x5c\x8e\xa6\x82\xcc\x83\x65\x83\x58\x83\x67", "表示のテスト"},
+		{"Shift_JIS", "\x93\xfa\x96\x7b\x8c\xea\x82\xcc\x83\x6a\x83\x85\x81\x5b\x83\x58", "日本語のニュース"},
+		{"euc-jp", "\xc6\xfc\xcb\xdc\xb8\xec\xa4\xce\xa5\xcb\xa5\xe5\xa1\xbc\xa5\xb9", "日本語のニュース"},
+		{"gb2312", "\xd6\xd0\xce\xc4\xd0\xc2\xce\xc5", "中文新闻"},
+		{"gbk", "\xd6\xd0\xce\xc4\xd0\xc2\xce\xc5", "中文新闻"},
+		{"windows-1251", "\xcd\xee\xe2\xee\xf1\xf2\xe8 \xe4\xed\xff", "Новости дня"},
+	}
+	for _, test := range tests {
+		page := `<html><head><meta charset="` + test.charset + `"><title>` + test.data +
+			"</title></head><body><p>" + test.data + "</p></body></html>"
+		// Detect the declared charset and pass it explicitly.
+		for _, label := range []string{"", test.charset} {
+			doc, err := NewDocumentWithCharset(strings.NewReader(page), label)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if title := doc.Title.String(); title != test.want {
+				t.Errorf("%s: title %q, want %q", test.charset, title, test.want)
+			}
+			if got := chunkTexts(doc); got != test.want {
+				t.Errorf("%s: text %q, want %q", test.charset, got, test.want)
+			}
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	page := "<html><head><meta charset=\"utf-8\"><title>Bad \xff title</title></head>" +
+		"<body><p>Bad \xe2\x82 text</p></body></html>"
+	doc, err := NewDocumentFromString(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := doc.Title.String(); title != "Bad \uFFFD title" {
+		t.Errorf("got title %q", title)
+	}
+	if got := chunkTexts(doc); got != "Bad \uFFFD text" {
+		t.Errorf("got chunks %q", got)
+	}
+}
+
+func TestDocumentContext(t *testing.T) {
+	const page = "<html><head><title>Hello</title></head><body><p>World</p></body></html>"
+
+	if _, err := NewDocumentContext(context.Background(), strings.NewReader(page)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewDocumentContext(ctx, strings.NewReader(page)); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	errBroken := errors.New("broken connection")
+	for _, trackOffsets := range []bool{false, true} {
+		parser := NewParser()
+		parser.TrackOffsets = trackOffsets
+		_, err := parser.Parse(io.MultiReader(
+			strings.NewReader("<html><body><p>Hello"), iotest.ErrReader(errBroken)))
+		if !errors.Is(err, ErrParse) || !errors.Is(err, errBroken) {
+			t.Errorf("expected ErrParse wrapping %v, got %v", errBroken, err)
+		}
+		if errors.Is(err, ErrNoHTML) || errors.Is(err, ErrNoBody) {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
+
+func TestMissingElements(t *testing.T) {
+	tests := []struct {
+		page  string
+		title string
+		text  string
+	}{
+		{`<p>Headless fragment</p>`, "", "Headless fragment"},
+		{`<body><h1>Body</h1><p>only</p></body>`, "", "Body only"},
+		{`<title>Hello</title><p>World</p>`, "Hello", "World"},
+		{`<html><body><p>No head</p></body></html>`, "", "No head"},
+	}
+	for _, test := range tests {
+		doc, err := NewDocumentFromString(test.page)
+		if err != nil {
+			t.Errorf("unexpected error %v for %q", err, test.page)
+			continue
+		}
+		if doc.Head() == nil || doc.Body() == nil {
+			t.Errorf("missing head or body for %q", test.page)
+		}
+		if title := doc.Title.String(); title != test.title {
+			t.Errorf("got title %q, want %q", title, test.title)
+		}
+		text := make([]string, 0, len(doc.Chunks))
+		for _, chunk := range doc.Chunks {
+			text = append(text, chunk.Text.String())
+		}
+		if got := strings.Join(text, " "); got != test.text {
+			t.Errorf("got text %q, want %q", got, test.text)
+		}
+	}
+
+	// Pages made of frames have no body.
+	page := `<html><head><title>Frames</title></head><frameset><frame src="a.html"></frameset></html>`
+	if _, err := NewDocumentFromString(page); err != ErrNoBody {
+		t.Errorf("expected ErrNoBody, got %v", err)
+	}
+}
+
+func TestDocumentFragment(t *testing.T) {
+	const fragment = `<div class="story">
+		<h2>Storm hits the coast</h2>
+		<p>A powerful storm swept across the coast.</p>
+		<p>Crews worked through the night.</p>
+	</div>`
+	want := []string{
+		"Storm hits the coast",
+		"A powerful storm swept across the coast.",
+		"Crews worked through the night.",
+	}
+	doc, err := NewDocumentFragment(strings.NewReader(fragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if doc.Head() == nil || doc.Body() == nil {
+			t.Fatalf("missing head or body")
+		}
+		if first := doc.Body().FirstChild; first == nil || first.Data != "div" {
+			t.Errorf("fragment isn't the body's content")
+		}
+		if got := chunkTexts(doc); got != strings.Join(want, ",") {
+			t.Errorf("got chunks %q", got)
+		}
+		// Reset keeps parsing fragments.
+		if err := doc.Reset(strings.NewReader(fragment)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	nested := func(depth int) string {
+		return "<html><body><p>Shallow text</p>" + strings.Repeat("<div>", depth) + "Deep text" +
+			strings.Repeat("</div>", depth) + "<p>More text</p></body></html>"
+	}
+
+	for _, trackOffsets := range []bool{false, true} {
+		p := NewParser()
+		p.MaxDepth = 100
+		p.TrackOffsets = trackOffsets
+		doc, err := p.Parse(strings.NewReader(nested(400)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !doc.Truncated {
+			t.Errorf("document wasn't truncated")
+		}
+		if got := chunkTexts(doc); got != "Shallow text,More text" {
+			t.Errorf("got chunks %q", got)
+		}
+	}
+
+	doc, err := NewDocumentFromString(nested(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Truncated || chunkTexts(doc) != "Shallow text,Deep text,More text" {
+		t.Errorf("unexpected truncation: %q", chunkTexts(doc))
+	}
+
+	// The default depth prunes nesting the HTML parser still accepts.
+	doc, err = NewDocumentFromString(nested(400))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Truncated || chunkTexts(doc) != "Shallow text,More text" {
+		t.Errorf("default MaxDepth didn't prune: %q", chunkTexts(doc))
+	}
+
+	// The HTML parser itself refuses to nest elements that deep.
+	if _, err := NewDocumentFromString(nested(20000)); !errors.Is(err, ErrParse) {
+		t.Errorf("expected ErrParse, got %v", err)
+	}
+}
+
+func TestChunkOffsets(t *testing.T) {
+	const page = `<html><head><title>Offsets</title></head><body>
+	<h1>Fish &amp; <em>Chips</em></h1>
+	<p>First paragraph.</p>
+	<p>Second <a href="/">link</a> paragraph.</p>
+</body></html>`
+
+	want := []string{
+		"Fish &amp; <em>Chips",
+		"First paragraph.",
+		"Second ",
+		"link",
+		" paragraph.",
+	}
+
+	p := NewParser()
+	p.TrackOffsets = true
+	doc, err := p.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Chunks) != len(want) {
+		t.Fatalf("unexpected number of chunks: %d", len(doc.Chunks))
+	}
+	for i, chunk := range doc.Chunks {
+		if chunk.Offset < 0 {
+			t.Errorf("chunk %d has no offset", i)
+			continue
+		}
+		if got := page[chunk.Offset : chunk.Offset+chunk.Length]; got != want[i] {
+			t.Errorf("chunk %d: got %q, want %q", i, got, want[i])
+		}
+	}
+
+	if doc, _ := NewDocument(strings.NewReader(page)); doc.Chunks[0].Offset != -1 {
+		t.Errorf("offsets were tracked by default")
+	}
+}
+
+func TestNewDocumentFrom(t *testing.T) {
+	const page = "<html><head><title>Hello</title></head><body><h1>Hello</h1><p>World</p></body></html>"
+
+	want, err := NewDocument(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromBytes, err := NewDocumentFromBytes([]byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromString, err := NewDocumentFromString(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []*Document{fromBytes, fromString} {
+		if doc.Title.String() != want.Title.String() || chunkTexts(doc) != chunkTexts(want) {
+			t.Errorf("documents differ")
+		}
+	}
+}
+
+func TestChunkAncestors(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p>Outside</p>
+		<article>
+			<p>Article</p>
+			<blockquote>
+				<blockquote><p>Nested quote</p></blockquote>
+				<ul><li>Quoted item</li></ul>
+				<p>Quote</p>
+			</blockquote>
+			<p>Article again</p>
+		</article>
+		<aside><ol><li>Aside item</li></ol></aside>
+		<main><p>Main</p><article><p>Main article</p></article></main>
+		<section><p>Section</p><section><p>Nested section</p></section></section>`)
+
+	want := map[string]int{
+		"Outside":        0,
+		"Article":        AncestorArticle,
+		"Nested quote":   AncestorArticle | AncestorBlockquote,
+		"Quoted item":    AncestorArticle | AncestorBlockquote | AncestorList,
+		"Quote":          AncestorArticle | AncestorBlockquote,
+		"Article again":  AncestorArticle,
+		"Aside item":     AncestorAside | AncestorList,
+		"Main":           AncestorMain,
+		"Main article":   AncestorMain | AncestorArticle,
+		"Section":        AncestorSection,
+		"Nested section": AncestorSection,
+	}
+	if len(doc.Chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(doc.Chunks), len(want))
+	}
+	for _, chunk := range doc.Chunks {
+		text := chunk.Text.String()
+		if chunk.Ancestors != want[text] {
+			t.Errorf("chunk %q has ancestors %b, want %b", text, chunk.Ancestors, want[text])
+		}
+	}
+}
+
+func TestWhitespace(t *testing.T) {
+	doc, err := NewDocumentFromString(`<html>
+		<head>
+			<title>
+				Storm hits
+				the coast
+			</title>
+		</head>
+		<body>
+			<div>
+				<p>
+					A powerful storm
+					swept across the
+						northern coast.
+				</p>
+				<p>Sibling<b>text</b>nodes   <i>  stay  </i>apart.</p>
+			</div>
+		</body>
+	</html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := doc.Title.String(); title != "Storm hits the coast" {
+		t.Errorf("got title %q", title)
+	}
+	if got := chunkTexts(doc); got != "A powerful storm swept across the northern coast.,Sibling,text,nodes,stay,apart." {
+		t.Errorf("got chunks %q", got)
+	}
+}
+
+func TestEntities(t *testing.T) {
+	doc, err := NewDocumentFromString(`<html>
+		<head><title>Tom &amp; Jerry&#8217;s show</title></head>
+		<body><p>It&#x2019;s &ldquo;back&rdquo; &mdash; finally&hellip;</p></body>
+	</html>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := doc.Title.String(); title != "Tom & Jerry’s show" {
+		t.Errorf("got title %q", title)
+	}
+	if got := chunkTexts(doc); got != "It’s “back” — finally…" {
+		t.Errorf("got chunks %q", got)
+	}
+}
+
+func TestChunkHeadingLevel(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<h1>One</h1><h2>Two</h2><h3><a href="/3">Three</a></h3>
+		<h4><span>Four</span></h4><h5>Five</h5><h6>Six</h6>
+		<p>Text with <b>bold</b> words</p>`)
+
+	want := map[string]int{"One": 1, "Two": 2, "Three": 3, "Four": 4, "Five": 5, "Six": 6, "Text with": 0, "bold": 0, "words": 0}
+	if len(doc.Chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(doc.Chunks), len(want))
+	}
+	for _, chunk := range doc.Chunks {
+		text := chunk.Text.String()
+		if chunk.HeadingLevel != want[text] || chunk.IsHeading() != (want[text] > 0) {
+			t.Errorf("chunk %q has heading level %d, want %d", text, chunk.HeadingLevel, want[text])
+		}
+	}
+}
+
+// largePage returns a synthetic page with n paragraphs, each followed by a
+// large inline script.
+func largePage(n int) string {
+	script := "<script>var data = \"" + strings.Repeat("x", 64*1024) + "\";</script>\n"
+	page := `<html><head><title>Large</title>
+		<style>p { color: black; }</style>
+		<script type="application/ld+json">{"@type": "NewsArticle", "author": {"name": "Jane Doe"}}</script>
+		</head><body>`
+	for i := 0; i < n; i++ {
+		page += "<!-- paragraph -->\n<p>Paragraph number " + strconv.Itoa(i) + " of the large page.</p>\n" + script
+	}
+	page += `<textarea>Comment here</textarea><noscript><p>Enable JavaScript</p></noscript></body></html>`
+	return page
+}
+
+func TestDocumentStreaming(t *testing.T) {
+	page := largePage(100)
+	want, err := NewDocument(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewDocumentStreaming(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title.String() != want.Title.String() {
+		t.Errorf("title %q, want %q", got.Title, want.Title)
+	}
+	if a, b := chunkTexts(got), chunkTexts(want); a != b {
+		t.Errorf("chunks differ:\n%s\n%s", a, b)
+	}
+	if len(got.Chunks) != 100 {
+		t.Errorf("got %d chunks", len(got.Chunks))
+	}
+	if author := got.Author(); author != "Jane Doe" {
+		t.Errorf("JSON-LD was dropped, author %q", author)
+	}
+}
+
+func benchmarkParse(b *testing.B, streaming bool) {
+	page := largePage(100)
+	parser := NewParser()
+	parser.Streaming = streaming
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(strings.NewReader(page)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B)          { benchmarkParse(b, false) }
+func BenchmarkParseStreaming(b *testing.B) { benchmarkParse(b, true) }
+
+func TestLinkDensity(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<ul class="nav">
+			<li><a href="/world">World</a></li>
+			<li><a href="/sports">Sports</a></li>
+		</ul>
+		<p>A long paragraph of text with a single <a href="/x">link</a> at the end.</p>
+		<p>Plain text.</p>`)
+
+	want := map[string][2]float32{
+		"World":       {1.0, 1.0},
+		"Sports":      {1.0, 1.0},
+		"link":        {0.05, 0.15},
+		"Plain text.": {0.0, 0.0},
+	}
+	for _, chunk := range doc.Chunks {
+		text := chunk.Text.String()
+		r, ok := want[text]
+		if !ok {
+			continue
+		}
+		if d := doc.LinkDensity(chunk); d < r[0] || d > r[1] {
+			t.Errorf("chunk %q has link density %f", text, d)
+		}
+		if d := doc.LinkDensity(chunk); d != chunk.LinkText {
+			t.Errorf("chunk %q: LinkDensity %f differs from LinkText %f", text, d, chunk.LinkText)
+		}
+	}
+}
+
+// markupPage returns a synthetic page with n sections of nested, richly
+// formatted paragraphs, lists and links.
+func markupPage(n int) string {
+	page := "<html><head><title>Markup</title></head><body>"
+	for i := 0; i < n; i++ {
+		page += `<div class="section"><h2><a href="/s">Section</a></h2>
+			<p>Some <b>bold</b>, <i>italic</i> and <a href="/x"><span>linked</span> text</a> in a
+			<em>paragraph</em> with a <code>code</code> span and <strong>strong <i>nested</i></strong> words.</p>
+			<ul><li><a href="/a">First</a></li><li><a href="/b">Second</a> item</li></ul>
+			<div><div><p>Deeply <span><span>nested</span></span> text.</p></div></div></div>`
+	}
+	return page + "</body></html>"
+}
+
+func BenchmarkParseMarkup(b *testing.B) {
+	page := markupPage(500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDocument(strings.NewReader(page)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	pages := []string{
+		largePage(20),
+		`<html><head><title>Small</title></head><body><p>One <a href="/a">link</a></p><img src="a.jpg"></body></html>`,
+		largePage(5),
+	}
+	doc, err := NewDocument(strings.NewReader(pages[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range pages[1:] {
+		if err := doc.Reset(strings.NewReader(page)); err != nil {
+			t.Fatal(err)
+		}
+		want, err := NewDocument(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.Title.String() != want.Title.String() {
+			t.Errorf("title %q, want %q", doc.Title, want.Title)
+		}
+		if a, b := chunkTexts(doc), chunkTexts(want); a != b {
+			t.Errorf("chunks differ:\n%s\n%s", a, b)
+		}
+		for i, chunk := range doc.Chunks {
+			if chunk.LinkText != want.Chunks[i].LinkText || chunk.Prev != nil && chunk.Prev != doc.Chunks[i-1] {
+				t.Errorf("chunk %d differs", i)
+			}
+		}
+		if len(doc.Images) != len(want.Images) || doc.Author() != want.Author() {
+			t.Errorf("metadata differs")
+		}
+	}
+}
+
+func BenchmarkReset(b *testing.B) {
+	page := largePage(100)
+	doc, err := NewDocument(strings.NewReader(page))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := doc.Reset(strings.NewReader(page)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestHiddenElements(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p style="display: none">Display</p>
+		<p style="color: red; VISIBILITY:hidden">Visibility</p>
+		<div hidden><p>Hidden</p></div>
+		<span aria-hidden="true">Aria</span>
+		<span aria-hidden="false">Visible aria</span>
+		<p style="visibility: visible">Visible</p>`)
+
+	if got := chunkTexts(doc); got != "Visible aria,Visible" {
+		t.Errorf("got chunks %q", got)
+	}
+}
+
+func TestGetClassStatsSorted(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<div class="b"><p>Three words here</p></div>
+		<div class="a"><p>Three other words</p></div>
+		<div class="main text"><p>The article text has the most words of all.</p><p>More words.</p></div>
+		<div class="c"><p>Few</p></div>`)
+
+	want := "main:10:2 text:10:2 a:3:1 b:3:1 c:1:1"
+	for i := 0; i < 10; i++ {
+		parts := make([]string, 0)
+		for _, stat := range doc.GetClassStatsSorted() {
+			parts = append(parts, stat.Class+":"+strconv.Itoa(stat.Words)+":"+strconv.Itoa(stat.Count))
+		}
+		if got := strings.Join(parts, " "); got != want {
+			t.Fatalf("GetClassStatsSorted() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package html
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strings"
+)
+
+// An Embed is a video of a hosting service embedded in the HTML document
+// using an <iframe> element.
+type Embed struct {
+	Provider string // name of the hosting service, "youtube" or "vimeo"
+	URL      string // URL of the video's page
+	ID       string // ID of the video at the hosting service
+}
+
+// youTubeHosts are the hosts of embedded YouTube players.
+var youTubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// isVideoID returns true if s looks like the ID of a video.
+func isVideoID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEmbed returns the Embed of the player URL src or nil if src doesn't
+// point to a YouTube or Vimeo player, e.g. https://www.youtube.com/embed/ID
+// or https://player.vimeo.com/video/ID.
+func parseEmbed(src string) *Embed {
+	u, err := url.Parse(strings.TrimSpace(src))
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Host)
+	switch {
+	case youTubeHosts[host]:
+		if id := strings.TrimPrefix(u.Path, "/embed/"); id != u.Path && isVideoID(id) {
+			return &Embed{Provider: "youtube", URL: "https://www.youtube.com/watch?v=" + id, ID: id}
+		}
+	case host == "player.vimeo.com":
+		if id := strings.TrimPrefix(u.Path, "/video/"); id != u.Path && isVideoID(id) {
+			return &Embed{Provider: "vimeo", URL: "https://vimeo.com/" + id, ID: id}
+		}
+	}
+	return nil
+}
+
+// findEmbeds returns the videos embedded by <iframe> elements below root.
+// Lazy loading scripts keep the player's URL in a data-src attribute.
+func findEmbeds(root *html.Node) []Embed {
+	result := make([]Embed, 0)
+	IterateNode(root, func(n *html.Node) int {
+		if n.Type != html.ElementNode || n.DataAtom != atom.Iframe {
+			return IterNext
+		}
+		for _, key := range []string{"src", "data-src"} {
+			if embed := parseEmbed(getAttribute(n, key)); embed != nil {
+				result = append(result, *embed)
+				break
+			}
+		}
+		return IterSkip
+	})
+	return result
+}
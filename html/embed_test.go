@@ -0,0 +1,34 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestEmbeds(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p>Watch the storm:</p>
+		<iframe width="560" height="315" src="https://www.youtube.com/embed/dQw4w9WgXcQ?rel=0" allowfullscreen></iframe>
+		<iframe src="//player.vimeo.com/video/76979871?title=0"></iframe>
+		<iframe data-src="https://www.youtube-nocookie.com/embed/abc_DEF-123" src="about:blank"></iframe>
+		<iframe src="https://www.youtube.com/watch?v=notAnEmbed"></iframe>
+		<iframe src="https://example.com/embed/map"></iframe>
+		<p>More text.</p>`)
+
+	want := []Embed{
+		{"youtube", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"vimeo", "https://vimeo.com/76979871", "76979871"},
+		{"youtube", "https://www.youtube.com/watch?v=abc_DEF-123", "abc_DEF-123"},
+	}
+	if len(doc.Embeds) != len(want) {
+		t.Fatalf("got embeds %v", doc.Embeds)
+	}
+	for i, embed := range doc.Embeds {
+		if embed != want[i] {
+			t.Errorf("got embed %+v, want %+v", embed, want[i])
+		}
+	}
+	// The iframes are still stripped from the text.
+	if got := chunkTexts(doc); got != "Watch the storm:,More text." {
+		t.Errorf("got chunks %q", got)
+	}
+}
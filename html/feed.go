@@ -0,0 +1,212 @@
+package html
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Errors returned by ParseFeed.
+var (
+	ErrNoFeed = errors.New("not an RSS, Atom or JSON feed")
+)
+
+// rssItem is an item of RSS 1.0 and 2.0 feeds.
+type rssItem struct {
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+	GUID  struct {
+		Value       string `xml:",chardata"`
+		IsPermaLink string `xml:"isPermaLink,attr"`
+	} `xml:"guid"`
+}
+
+// atomEntry is an entry of Atom feeds.
+type atomEntry struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Rel  string `xml:"rel,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+// xmlFeed covers the elements of RSS 1.0, RSS 2.0 and Atom feeds we need.
+type xmlFeed struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// jsonFeed covers the elements of JSON feeds we need.
+type jsonFeed struct {
+	Version string `json:"version"`
+	Items   []struct {
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		ExternalURL string `json:"external_url"`
+	} `json:"items"`
+}
+
+// newFeedLink returns a link with the trimmed title and url or nil if url
+// is empty.
+func newFeedLink(title string, url string) *util.Link {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil
+	}
+	return &util.Link{Text: strings.Join(strings.Fields(title), " "), URL: url}
+}
+
+// ParseFeed reads an RSS, Atom or JSON feed from r and returns the links of
+// its items in feed order. The link texts are the item titles. Items
+// without link are left out.
+func ParseFeed(r io.Reader) ([]*util.Link, error) {
+	// Skip whitespace and byte order marks to find out whether the feed is
+	// JSON or XML.
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			return nil, ErrNoFeed
+		}
+		if !unicode.IsSpace(c) && c != '\ufeff' {
+			br.UnreadRune()
+			if c == '{' {
+				return parseJSONFeed(br)
+			}
+			break
+		}
+	}
+	return parseXMLFeed(br)
+}
+
+func parseJSONFeed(r io.Reader) ([]*util.Link, error) {
+	var feed jsonFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(feed.Version, "https://jsonfeed.org/") {
+		return nil, ErrNoFeed
+	}
+	result := make([]*util.Link, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		url := item.URL
+		if url == "" {
+			url = item.ExternalURL
+		}
+		if link := newFeedLink(item.Title, url); link != nil {
+			result = append(result, link)
+		}
+	}
+	return result, nil
+}
+
+func parseXMLFeed(r io.Reader) ([]*util.Link, error) {
+	var feed xmlFeed
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(&feed); err != nil {
+		return nil, err
+	}
+	result := make([]*util.Link, 0)
+	switch feed.XMLName.Local {
+	case "rss", "RDF":
+		for _, item := range append(feed.Channel.Items, feed.Items...) {
+			url := item.Link
+			if url == "" && !strings.EqualFold(item.GUID.IsPermaLink, "false") {
+				url = item.GUID.Value
+			}
+			if link := newFeedLink(item.Title, url); link != nil {
+				result = append(result, link)
+			}
+		}
+	case "feed":
+		for _, entry := range feed.Entries {
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					if link := newFeedLink(entry.Title, l.Href); link != nil {
+						result = append(result, link)
+					}
+					break
+				}
+			}
+		}
+	default:
+		return nil, ErrNoFeed
+	}
+	return result, nil
+}
+
+// feedTypes maps the MIME types of <link rel="alternate"> elements
+// announcing feeds to the FeedTypes of their links.
+var feedTypes = map[string]string{
+	"application/atom+xml":  "atom",
+	"application/feed+json": "json",
+	"application/json":      "json",
+	"application/rss+xml":   "rss",
+}
+
+// findFeeds returns the feeds announced by <link rel="alternate"> elements
+// and the links in the document whose URLs look like feeds, in document
+// order. Many sites link their feeds in the footer only, so the search has
+// to happen before cleaning.
+func findFeeds(root *html.Node) []util.Link {
+	result := make([]util.Link, 0)
+	IterateNode(root, func(n *html.Node) int {
+		if n.Type != html.ElementNode {
+			return IterNext
+		}
+		switch n.DataAtom {
+		case atom.Link:
+			typ := feedTypes[strings.ToLower(strings.TrimSpace(getAttribute(n, "type")))]
+			if hasRel(n, "alternate") && typ != "" {
+				result = append(result, util.Link{Text: strings.TrimSpace(getAttribute(n, "title")), URL: getAttribute(n, "href"), FeedType: typ})
+			}
+		case atom.A:
+			link := util.Link{URL: getAttribute(n, "href")}
+			if link.IsFeed() {
+				text := util.NewText()
+				IterateText(n, text.WriteString)
+				link.Text = text.String()
+				result = append(result, link)
+			}
+			return IterSkip
+		}
+		return IterNext
+	})
+	return result
+}
+
+// Feeds returns the document's feeds, resolved against the URL base or the
+// document's URL if base is empty. Feeds announced in the head come first,
+// followed by links in the body whose URLs look like feeds, see
+// util.Link.IsFeed. Each URL is returned once. The FeedType of announced
+// feeds is derived from their MIME type, the FeedType of the other feeds is
+// empty.
+func (doc *Document) Feeds(base string) []*util.Link {
+	result := make([]*util.Link, 0, len(doc.feeds))
+	seen := make(map[string]bool)
+	for _, feed := range doc.feeds {
+		if strings.TrimSpace(feed.URL) == "" {
+			continue
+		}
+		url, err := doc.resolveLink(base, feed.URL)
+		if err != nil || seen[url] {
+			continue
+		}
+		seen[url] = true
+		result = append(result, &util.Link{Text: feed.Text, URL: url, FeedType: feed.FeedType})
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package html
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		feed string
+		want string
+	}{
+		{
+			`<?xml version="1.0" encoding="UTF-8"?>
+			<rss version="2.0"><channel>
+				<title>Example News</title>
+				<link>http://example.com/</link>
+				<item><title>Storm hits
+					the coast</title><link>http://example.com/storm</link></item>
+				<item><title>No link</title></item>
+				<item><title>Permalink</title><guid>http://example.com/guid</guid></item>
+				<item><title>Not a permalink</title><guid isPermaLink="false">1234</guid></item>
+			</channel></rss>`,
+			"[Storm hits the coast|http://example.com/storm Permalink|http://example.com/guid]",
+		},
+		{
+			`<?xml version="1.0" encoding="ISO-8859-1"?>
+			<feed xmlns="http://www.w3.org/2005/Atom">
+				<title>Example News</title>
+				<link href="http://example.com/"/>
+				<entry>
+					<title>Caf` + "\xe9" + ` opens</title>
+					<link rel="edit" href="http://example.com/edit/1"/>
+					<link rel="alternate" href="http://example.com/cafe"/>
+				</entry>
+				<entry><title>Plain</title><link href="http://example.com/plain"/></entry>
+			</feed>`,
+			"[Café opens|http://example.com/cafe Plain|http://example.com/plain]",
+		},
+		{
+			` {"version": "https://jsonfeed.org/version/1.1", "title": "Example News",
+				"items": [
+					{"id": "1", "title": "First", "url": "http://example.com/1"},
+					{"id": "2", "external_url": "http://other.com/2"},
+					{"id": "3", "title": "None"}
+				]}`,
+			"[First|http://example.com/1 |http://other.com/2]",
+		},
+	}
+	for _, test := range tests {
+		links, err := ParseFeed(strings.NewReader(test.feed))
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+		parts := make([]string, 0)
+		for _, link := range links {
+			parts = append(parts, link.Text+"|"+link.URL)
+		}
+		if got := fmt.Sprint(parts); got != test.want {
+			t.Errorf("ParseFeed() = %s, want %s", got, test.want)
+		}
+	}
+
+	for _, data := range []string{"<html><body></body></html>", `{"items": []}`, ""} {
+		if _, err := ParseFeed(strings.NewReader(data)); err != ErrNoFeed {
+			t.Errorf("%q: expected ErrNoFeed, got %v", data, err)
+		}
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	doc := newTestDocument(t, `
+		<link rel="alternate" type="application/rss+xml" title="All news" href="/rss/all.xml">
+		<link rel="alternate" type="text/html" hreflang="de" href="/de/">
+		<link rel="alternate" type="application/atom+xml" href="javascript:void(0)">`, `
+		<p>Read the <a href="/2014/storm.html">story</a>.</p>
+		<footer>
+			<a href="/rss/all.xml">RSS</a>
+			<a href="/feed/atom/">Atom feed</a>
+			<a href="/sitemap.xml">Sitemap</a>
+		</footer>`)
+
+	want := []string{
+		"All news http://example.com/rss/all.xml rss",
+		"Atom feed http://example.com/feed/atom/ ",
+	}
+	feeds := doc.Feeds("http://example.com/news/1")
+	if len(feeds) != len(want) {
+		t.Fatalf("got %d feeds, want %d", len(feeds), len(want))
+	}
+	for i, feed := range feeds {
+		if got := feed.Text + " " + feed.URL + " " + feed.FeedType; got != want[i] {
+			t.Errorf("got feed %q, want %q", got, want[i])
+		}
+	}
+
+	// Pages often announce several types of the same feed.
+	doc = newTestDocument(t, `
+		<link rel="alternate" type="application/rss+xml" href="/feed.rss">
+		<link rel="alternate" type="application/atom+xml" href="/feed.atom">
+		<link rel="alternate" type="application/feed+json" href="/feed.json">
+		<link rel="alternate" type="application/json" href="/feed2.json">
+		<link rel="stylesheet" type="application/rss+xml" href="/style.rss">`, "<p>Hello</p>")
+	types := make([]string, 0)
+	for _, feed := range doc.Feeds("http://example.com/") {
+		types = append(types, feed.FeedType)
+	}
+	if got := strings.Join(types, ","); got != "rss,atom,json,json" {
+		t.Errorf("got feed types %q", got)
+	}
+
+	if feeds := newTestDocument(t, "", "<p>Hello</p>").Feeds(""); feeds == nil || len(feeds) != 0 {
+		t.Errorf("unexpected feeds %v", feeds)
+	}
+}
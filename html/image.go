@@ -0,0 +1,181 @@
+package html
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strconv"
+	"strings"
+)
+
+// An Image is an <img> or <amp-img> element found in the HTML document.
+type Image struct {
+	URL    string     // source of the image, see imageSource
+	Alt    string     // alternative text
+	Width  int        // declared width or zero if unknown
+	Height int        // declared height or zero if unknown
+	Index  int        // number of chunks preceding this image in the document
+	Base   *html.Node // the image element
+}
+
+// parseDimension parses the value of a width or height attribute. It
+// returns zero for relative and invalid values.
+func parseDimension(s string) int {
+	if val, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(s), "px")); err == nil && val > 0 {
+		return val
+	}
+	return 0
+}
+
+// isImage returns true if n is an <img> or <amp-img> element.
+func isImage(n *html.Node) bool {
+	return n.Type == html.ElementNode && (n.DataAtom == atom.Img || n.Data == "amp-img")
+}
+
+// lazySourceAttrs are the attributes lazy loading scripts read the image's
+// actual source from. The src attribute holds a placeholder until then.
+var lazySourceAttrs = []string{"data-srcset", "srcset", "data-src", "data-lazy-src", "data-original", "src"}
+
+// imageSource returns the source URL of the image element n. It prefers
+// the largest candidate of srcset attributes, followed by the attributes
+// of lazy loading scripts and finally src. Inline data URLs, which are
+// mostly used for placeholders, are skipped. Images inside of <picture>
+// elements use the candidate of the <source> elements if it's wider or the
+// image itself has no source.
+func imageSource(n *html.Node) string {
+	src, width := "", 0.0
+	for _, key := range lazySourceAttrs {
+		val, w := strings.TrimSpace(getAttribute(n, key)), 0.0
+		if strings.HasSuffix(key, "srcset") {
+			val, w = bestSrcset(val)
+		}
+		if val != "" && !isDataURL(val) {
+			src, width = val, w
+			break
+		}
+	}
+	if p := n.Parent; p != nil && p.DataAtom == atom.Picture {
+		for c := p.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Source {
+				continue
+			}
+			for _, key := range []string{"data-srcset", "srcset"} {
+				url, w := bestSrcset(getAttribute(c, key))
+				if url == "" || isDataURL(url) {
+					continue
+				}
+				if src == "" || w > width {
+					src, width = url, w
+				}
+				break
+			}
+		}
+	}
+	return src
+}
+
+// isDataURL returns true if s is an inline data URL.
+func isDataURL(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), "data:")
+}
+
+// parseSrcset returns the URL of the largest image candidate in the srcset
+// attribute value s. Width descriptors like "640w" take precedence over
+// pixel density descriptors like "2x". Candidates without descriptor count
+// as "1x".
+func parseSrcset(s string) string {
+	url, _ := bestSrcset(s)
+	return url
+}
+
+// bestSrcset works like parseSrcset, but also returns the width of the
+// candidate, which is zero if the candidates have no width descriptors.
+func bestSrcset(s string) (string, float64) {
+	const space = " \t\n\r\f"
+	best, bestWidth, bestDensity := "", 0.0, 0.0
+	for {
+		s = strings.TrimLeft(s, space+",")
+		if s == "" {
+			break
+		}
+		// The URL ends at the next whitespace. Commas at its end separate
+		// it from the next candidate.
+		end := strings.IndexAny(s, space)
+		if end < 0 {
+			end = len(s)
+		}
+		url, descriptor := s[:end], ""
+		if s = s[end:]; strings.HasSuffix(url, ",") {
+			url = strings.TrimRight(url, ",")
+		} else if i := strings.IndexByte(s, ','); i >= 0 {
+			descriptor, s = s[:i], s[i+1:]
+		} else {
+			descriptor, s = s, ""
+		}
+		if strings.HasPrefix(strings.ToLower(url), "data:") {
+			continue
+		}
+
+		width, density := 0.0, 1.0
+		if descriptor = strings.TrimSpace(descriptor); descriptor != "" {
+			val, err := strconv.ParseFloat(descriptor[:len(descriptor)-1], 64)
+			if err != nil || val <= 0 {
+				continue
+			}
+			switch descriptor[len(descriptor)-1] {
+			case 'w':
+				width = val
+			case 'x':
+				density = val
+			default:
+				continue
+			}
+		}
+		if width > bestWidth || (bestWidth == 0 && width == 0 && density > bestDensity) {
+			best, bestWidth, bestDensity = url, width, density
+		}
+	}
+	return best, bestWidth
+}
+
+// NewImage creates an Image from the <img> or <amp-img> element n. It
+// returns nil if n has no source.
+func NewImage(doc *Document, n *html.Node) *Image {
+	src := imageSource(n)
+	if src == "" {
+		return nil
+	}
+	return &Image{
+		URL:    src,
+		Alt:    strings.TrimSpace(getAttribute(n, "alt")),
+		Width:  parseDimension(getAttribute(n, "width")),
+		Height: parseDimension(getAttribute(n, "height")),
+		Index:  len(doc.Chunks),
+		Base:   n,
+	}
+}
+
+// Area returns the declared area of the image in pixels.
+func (img *Image) Area() int {
+	return img.Width * img.Height
+}
+
+// TopImage returns the URL of the article's lead image, resolved against the
+// URL base or the document's URL if base is empty. It prefers the image
+// announced by Open Graph or Twitter metadata and falls back to the largest
+// image in the body. If the document contains no images, TopImage returns an
+// empty string.
+func (doc *Document) TopImage(base string) string {
+	if src := doc.getMeta("og:image", "og:image:url", "twitter:image", "twitter:image:src"); src != "" {
+		return doc.resolveURL(base, src)
+	}
+	var best *Image = nil
+	for _, img := range doc.Images {
+		if best == nil || img.Area() > best.Area() {
+			best = img
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return doc.resolveURL(base, best.URL)
+}
@@ -0,0 +1,152 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestImages(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<nav><a href="/"><img src="logo.png" alt="Logo"></a></nav>
+		<div class="social-buttons"><img src="share.png"></div>
+		<article>
+			<h1>Headline</h1>
+			<img src="lead.jpg" alt=" A lead image " width="640" height="480px">
+			<p>Some text.</p>
+			<a href="large.jpg"><img src="small.jpg" width="50%"></a>
+		</article>`)
+
+	if len(doc.Images) != 2 {
+		t.Fatalf("unexpected number of images: %d", len(doc.Images))
+	}
+
+	lead := doc.Images[0]
+	if lead.URL != "lead.jpg" || lead.Alt != "A lead image" {
+		t.Errorf("unexpected image %+v", lead)
+	}
+	if lead.Width != 640 || lead.Height != 480 {
+		t.Errorf("unexpected dimensions %dx%d", lead.Width, lead.Height)
+	}
+	if lead.Index != 1 || doc.Chunks[lead.Index-1].Text.String() != "Headline" {
+		t.Errorf("unexpected index %d", lead.Index)
+	}
+
+	if small := doc.Images[1]; small.URL != "small.jpg" || small.Width != 0 || small.Index != 2 {
+		t.Errorf("unexpected image %+v", small)
+	}
+}
+
+func TestTopImage(t *testing.T) {
+	const body = `
+		<img src="/img/small.jpg" width="100" height="100">
+		<img src="/img/large.jpg" width="800" height="600">
+		<img src="/img/unknown.jpg">`
+
+	tests := []struct {
+		head string
+		body string
+		base string
+		want string
+	}{
+		{`<meta property="og:image" content="/img/og.jpg"><meta name="twitter:image" content="/img/tw.jpg">`, body, "http://example.com/news/1", "http://example.com/img/og.jpg"},
+		{`<meta name="twitter:image" content="http://cdn.example.com/tw.jpg">`, body, "http://example.com/news/1", "http://cdn.example.com/tw.jpg"},
+		{`<meta name="twitter:image" content="/img/tw.jpg"><meta property="og:image" content="/img/og.jpg">`, body, "http://example.com/news/1", "http://example.com/img/og.jpg"},
+		{``, body, "http://example.com/news/1", "http://example.com/img/large.jpg"},
+		{``, body, "", "/img/large.jpg"},
+		{``, `<p>No images.</p>`, "", ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, test.body).TopImage(test.base); got != test.want {
+			t.Errorf("TopImage() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		srcset string
+		want   string
+	}{
+		{`small.jpg 320w, large.jpg 1024w, medium.jpg 640w`, "large.jpg"},
+		{`a.jpg, b.jpg 2x,c.jpg 1.5x`, "b.jpg"},
+		{`retina.jpg 3x, wide.jpg 800w`, "wide.jpg"},
+		{`data:image/gif;base64,R0lGODlhAQABAAAAACw= 1x, /img/a,b.jpg 2x`, "/img/a,b.jpg"},
+		{`  single.jpg  `, "single.jpg"},
+		{`broken.jpg 10q, ok.jpg 100w`, "ok.jpg"},
+		{``, ""},
+	}
+	for _, test := range tests {
+		if got := parseSrcset(test.srcset); got != test.want {
+			t.Errorf("parseSrcset(%q) = %q, want %q", test.srcset, got, test.want)
+		}
+	}
+}
+
+func TestLazyImages(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" data-src="lazy.jpg">
+		<img src="blank.gif" data-lazy-src="lazy-src.jpg">
+		<img src="placeholder.gif" data-original="original.jpg">
+		<img src="small.jpg" srcset="small.jpg 320w, large.jpg 1024w">
+		<img data-srcset="a.jpg 1x, b.jpg 2x">
+		<amp-img src="amp.jpg" width="1200" height="800" layout="responsive"></amp-img>
+		<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">`)
+
+	want := []string{"lazy.jpg", "lazy-src.jpg", "original.jpg", "large.jpg", "b.jpg", "amp.jpg"}
+	if len(doc.Images) != len(want) {
+		t.Fatalf("got %d images, want %d", len(doc.Images), len(want))
+	}
+	for i, img := range doc.Images {
+		if img.URL != want[i] {
+			t.Errorf("image %d has URL %q, want %q", i, img.URL, want[i])
+		}
+	}
+	if got := doc.TopImage("http://example.com/"); got != "http://example.com/amp.jpg" {
+		t.Errorf("TopImage() = %q", got)
+	}
+}
+
+func TestPictureImages(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<picture>
+			<source media="(max-width: 600px)" srcset="lead-600.webp 600w, lead-300.webp 300w" type="image/webp">
+			<source media="(min-width: 601px)" srcset="lead-1200.jpg 1200w, lead-900.jpg 900w">
+			<img src="lead-default.jpg" alt="Lead">
+		</picture>
+		<picture>
+			<source srcset="data:image/gif;base64,R0lGODlhAQABAAAAACw=" data-srcset="lazy-2x.jpg 2x, lazy-1x.jpg">
+			<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+		</picture>
+		<picture>
+			<source srcset="retina.jpg 2x">
+			<img src="default.jpg" srcset="wide.jpg 800w">
+		</picture>
+		<picture>
+			<source srcset="other.jpg 2x">
+			<img src="fallback.jpg">
+		</picture>
+		<picture>
+			<source srcset="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+			<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+		</picture>
+		<picture>
+			<source srcset="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+			<source srcset="loaded.jpg">
+			<img>
+		</picture>`)
+
+	want := []string{"lead-1200.jpg", "lazy-2x.jpg", "wide.jpg", "fallback.jpg", "loaded.jpg"}
+	if len(doc.Images) != len(want) {
+		t.Fatalf("got %d images, want %d", len(doc.Images), len(want))
+	}
+	for i, img := range doc.Images {
+		if img.URL != want[i] {
+			t.Errorf("image %d has URL %q, want %q", i, img.URL, want[i])
+		}
+	}
+	if doc.Images[0].Alt != "Lead" {
+		t.Errorf("got alt text %q", doc.Images[0].Alt)
+	}
+	if got := doc.TopImage("http://example.com/"); got != "http://example.com/lead-1200.jpg" {
+		t.Errorf("TopImage() = %q", got)
+	}
+}
@@ -4,25 +4,31 @@ import (
 	"golang.org/x/net/html"
 )
 
+// The return values of IterateNode callbacks.
 const (
 	IterNext = iota // Keep going.
 	IterSkip        // Skip the current subtree, proceed with the next sibling.
 	IterStop        // Skip everything.
 )
 
-// iterateText iterates through the values of all text nodes.
-func iterateText(n *html.Node, callback func(s string)) {
+// IterateText calls callback with the data of every text node in the
+// subtree rooted at n, including n itself, in document order.
+func IterateText(n *html.Node, callback func(s string)) {
 	if n.Type == html.TextNode {
 		callback(n.Data)
 	}
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		iterateText(c, callback)
+		IterateText(c, callback)
 	}
 }
 
-// iterateNode iterates through all nodes. It further allows skipping and/or
-// stopping iteration through the return value of callback.
-func iterateNode(n *html.Node, callback func(s *html.Node) int) int {
+// IterateNode calls callback for every node in the subtree rooted at n,
+// including n itself, in document order. The return value of callback
+// controls the iteration: IterNext descends into the node's children,
+// IterSkip continues with the node's next sibling and IterStop ends the
+// iteration. IterateNode returns IterStop if the iteration was stopped and
+// IterNext otherwise.
+func IterateNode(n *html.Node, callback func(s *html.Node) int) int {
 	switch callback(n) {
 	case IterSkip:
 		return IterNext
@@ -30,7 +36,7 @@ func iterateNode(n *html.Node, callback func(s *html.Node) int) int {
 		return IterStop
 	}
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if iterateNode(c, callback) == IterStop {
+		if IterateNode(c, callback) == IterStop {
 			return IterStop
 		}
 	}
@@ -0,0 +1,47 @@
+package html
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+)
+
+func ExampleIterateNode() {
+	doc, err := NewDocument(strings.NewReader(`<html><body>
+		<ul><li><a href="/skipped">Skipped</a></li></ul>
+		<p>See <a href="/first">first</a>, <a href="/second">second</a> and <a href="/third">third</a>.</p>
+	</body></html>`))
+	if err != nil {
+		panic(err)
+	}
+	count := 0
+	IterateNode(doc.Body(), func(n *html.Node) int {
+		switch {
+		case n.DataAtom == atom.Ul:
+			return IterSkip
+		case n.DataAtom == atom.A:
+			fmt.Println(n.Attr[0].Val)
+			if count++; count == 2 {
+				return IterStop
+			}
+		}
+		return IterNext
+	})
+	// Output:
+	// /first
+	// /second
+}
+
+func ExampleIterateText() {
+	doc, err := NewDocument(strings.NewReader(`<html><body><p>Hello <b>World</b></p></body></html>`))
+	if err != nil {
+		panic(err)
+	}
+	IterateText(doc.Body(), func(s string) {
+		fmt.Printf("%q\n", s)
+	})
+	// Output:
+	// "Hello "
+	// "World"
+}
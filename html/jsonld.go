@@ -0,0 +1,110 @@
+package html
+
+import (
+	"encoding/json"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+)
+
+// parseJSONLD parses the JSON-LD scripts found below n. Arrays and @graph
+// containers are flattened, so the result contains a plain list of objects.
+// Invalid scripts are skipped.
+func parseJSONLD(n *html.Node) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+
+	var flatten func(v interface{})
+	flatten = func(v interface{}) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, item := range v {
+				flatten(item)
+			}
+		case map[string]interface{}:
+			if graph, ok := v["@graph"]; ok {
+				flatten(graph)
+				if _, ok := v["@type"]; !ok {
+					return
+				}
+			}
+			result = append(result, v)
+		}
+	}
+
+	IterateNode(n, func(n *html.Node) int {
+		if n.Type != html.ElementNode || n.DataAtom != atom.Script {
+			return IterNext
+		}
+		if !strings.EqualFold(strings.TrimSpace(getAttribute(n, "type")), "application/ld+json") {
+			return IterSkip
+		}
+		var data strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				data.WriteString(c.Data)
+			}
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(data.String()), &v); err == nil {
+			flatten(v)
+		}
+		return IterSkip
+	})
+	return result
+}
+
+// JSONLD returns the JSON-LD objects embedded in the document through
+// <script type="application/ld+json"> elements. Objects of @graph containers
+// and arrays are included individually.
+func (doc *Document) JSONLD() []map[string]interface{} {
+	return doc.jsonLD
+}
+
+// hasArticleType returns true if the JSON-LD object v has a schema.org
+// article type, e.g. Article, NewsArticle or BlogPosting.
+func hasArticleType(v map[string]interface{}) bool {
+	isArticle := func(t interface{}) bool {
+		s, ok := t.(string)
+		return ok && (strings.HasSuffix(s, "Article") || strings.HasSuffix(s, "BlogPosting"))
+	}
+	switch t := v["@type"].(type) {
+	case []interface{}:
+		for _, s := range t {
+			if isArticle(s) {
+				return true
+			}
+		}
+		return false
+	default:
+		return isArticle(t)
+	}
+}
+
+// getJSONLDArticle returns the first JSON-LD object of an article type or nil.
+func (doc *Document) getJSONLDArticle() map[string]interface{} {
+	for _, v := range doc.jsonLD {
+		if hasArticleType(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// getJSONLDNames returns the names of the JSON-LD value v, which is either
+// a name, an object with name property or an array of these.
+func getJSONLDNames(v interface{}) []string {
+	result := make([]string, 0)
+	switch v := v.(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			result = append(result, s)
+		}
+	case map[string]interface{}:
+		result = append(result, getJSONLDNames(v["name"])...)
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, getJSONLDNames(item)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package html
+
+import (
+	"testing"
+	"time"
+)
+
+const testJSONLD = `<script type="application/ld+json">
+{
+	"@context": "https://schema.org",
+	"@type": "NewsArticle",
+	"headline": "Storm hits the coast",
+	"image": ["https://example.com/storm.jpg"],
+	"datePublished": "2015-02-05T08:00:00+08:00",
+	"author": [
+		{"@type": "Person", "name": "Jane Doe"},
+		{"@type": "Person", "name": "John Smith"}
+	],
+	"publisher": {"@type": "Organization", "name": "Daily News"}
+}
+</script>`
+
+const testJSONLDGraph = `<script type="application/ld+json">
+{
+	"@context": "https://schema.org",
+	"@graph": [
+		{"@type": "WebSite", "name": "Daily News"},
+		{"@type": ["Article"], "author": "Jane Doe", "datePublished": "2015-02-05"}
+	]
+}
+</script>`
+
+func TestJSONLD(t *testing.T) {
+	// The script in the body must survive cleaning.
+	doc := newTestDocument(t, `<meta name="author" content="Someone Else">`, testJSONLD)
+	if objects := doc.JSONLD(); len(objects) != 1 || objects[0]["headline"] != "Storm hits the coast" {
+		t.Errorf("unexpected objects %v", objects)
+	}
+	if author := doc.Author(); author != "Jane Doe, John Smith" {
+		t.Errorf("unexpected author %q", author)
+	}
+	if date, err := doc.PublishedTime(); err != nil || !date.Equal(time.Date(2015, 2, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date %v", date)
+	}
+
+	doc = newTestDocument(t, testJSONLDGraph+`<script type="application/ld+json">{invalid</script>`, "")
+	if objects := doc.JSONLD(); len(objects) != 2 {
+		t.Errorf("unexpected objects %v", objects)
+	}
+	if author := doc.Author(); author != "Jane Doe" {
+		t.Errorf("unexpected author %q", author)
+	}
+}
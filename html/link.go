@@ -0,0 +1,329 @@
+package html
+
+import (
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Links returns the links of the document's chunks in document order with
+// their URLs resolved against base. This includes links inside headings,
+// like the headlines of teasers, and links wrapping images, whose text is
+// the alternative text of the images. The Context of each link is the text of
+// the nearest heading before it, which tells related articles and
+// navigation apart. Headings consisting of links only don't become the
+// Context. Target, Download and Rel reflect the attributes of the links and
+// the <base> element. Links of elements removed or ignored while parsing are
+// missing.
+func (doc *Document) Links(base string) []*util.Link {
+	result := make([]*util.Link, 0, 32)
+	doc.eachLink(base, func(block *html.Node, a *html.Node, link *util.Link) {
+		result = append(result, link)
+	})
+	return result
+}
+
+// UniqueLinks works like Links, but returns each link once. Links are
+// considered equal if their normalized URLs are, see util.Link.Normalized.
+// The first of equal links is kept.
+func (doc *Document) UniqueLinks(base string) []*util.Link {
+	result := make([]*util.Link, 0, 32)
+	seen := make(map[string]bool)
+	doc.eachLink(base, func(block *html.Node, a *html.Node, link *util.Link) {
+		if key := link.Normalized(); !seen[key] {
+			seen[key] = true
+			result = append(result, link)
+		}
+	})
+	return result
+}
+
+// InternalLinks returns the links returned by Links(base) that point to the
+// host of base or the document's URL if base is empty. Hosts with and
+// without "www." prefix are the same, other subdomains are different
+// hosts. Links without host, which remain if the base is unknown, are
+// internal.
+func (doc *Document) InternalLinks(base string) []*util.Link {
+	return doc.siteLinks(base, true)
+}
+
+// ExternalLinks returns the links returned by Links(base) that aren't
+// returned by InternalLinks(base).
+func (doc *Document) ExternalLinks(base string) []*util.Link {
+	return doc.siteLinks(base, false)
+}
+
+// siteLinks returns the internal or external links of the document.
+func (doc *Document) siteLinks(base string, internal bool) []*util.Link {
+	if base == "" {
+		base = doc.URL
+	}
+	host := siteHost(base)
+	result := make([]*util.Link, 0, 32)
+	doc.eachLink(base, func(block *html.Node, a *html.Node, link *util.Link) {
+		h := siteHost(link.URL)
+		if (h == "" || h == host) == internal {
+			result = append(result, link)
+		}
+	})
+	return result
+}
+
+// eachLink calls fn for each link of the document in document order, as
+// described by Links, along with the link's <a> element and its block.
+func (doc *Document) eachLink(base string, fn func(*html.Node, *html.Node, *util.Link)) {
+	context := ""
+
+	// Links wrapping images only have no chunk, so they are found through
+	// the document's images.
+	images := doc.Images
+	var prev *html.Node
+	imageLinks := func(index int) {
+		for ; len(images) > 0 && images[0].Index <= index; images = images[1:] {
+			a := enclosingLink(images[0].Base)
+			if a == nil || a == prev || hasText(a) {
+				continue
+			}
+			prev = a
+			if link := doc.newLink(base, a, anchorText(a), context); link != nil {
+				fn(getParentBlock(a), a, link)
+			}
+		}
+	}
+
+	for i, chunk := range doc.Chunks {
+		imageLinks(i)
+		if !chunk.IsHeading() {
+			if chunk.Base.DataAtom != atom.A {
+				continue
+			}
+			if link := doc.newLink(base, chunk.Base, chunk.Text.String(), context); link != nil {
+				fn(chunk.Block, chunk.Base, link)
+			}
+			continue
+		}
+		// Links inside headings are part of the heading's chunk, unless
+		// they wrap images only.
+		letters := 0
+		IterateNode(chunk.Base, func(n *html.Node) int {
+			if n.DataAtom != atom.A {
+				return IterNext
+			}
+			if !hasText(n) {
+				return IterSkip
+			}
+			text := anchorText(n)
+			letters += util.CountLetters(text)
+			if link := doc.newLink(base, n, text, context); link != nil {
+				fn(chunk.Block, n, link)
+			}
+			return IterSkip
+		})
+		if letters < chunk.Text.LetterCount() {
+			context = chunk.Text.String()
+		}
+	}
+	imageLinks(len(doc.Chunks))
+}
+
+// enclosingLink returns the <a> element enclosing n or nil if there is none.
+func enclosingLink(n *html.Node) *html.Node {
+	for ; n != nil; n = n.Parent {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			return n
+		}
+	}
+	return nil
+}
+
+// hasText returns true if n contains text other than whitespace.
+func hasText(n *html.Node) bool {
+	return IterateNode(n, func(c *html.Node) int {
+		if c.Type == html.TextNode && strings.TrimSpace(c.Data) != "" {
+			return IterStop
+		}
+		return IterNext
+	}) == IterStop
+}
+
+// anchorText returns the text of the <a> element a. Links without text
+// get the alternative texts of the images they wrap instead.
+func anchorText(a *html.Node) string {
+	text := util.NewText()
+	IterateText(a, text.WriteString)
+	if text.Len() == 0 {
+		IterateNode(a, func(n *html.Node) int {
+			if isImage(n) {
+				text.WriteString(getAttribute(n, "alt"))
+			}
+			return IterNext
+		})
+	}
+	return text.String()
+}
+
+// newLink returns the link of the <a> element a with the given text and
+// context or nil if a has no navigable href.
+func (doc *Document) newLink(base string, a *html.Node, text string, context string) *util.Link {
+	href := getAttribute(a, "href")
+	if href == "" {
+		return nil
+	}
+	ref, err := doc.resolveLink(base, href)
+	if err != nil {
+		return nil
+	}
+	link := &util.Link{Text: text, URL: ref, Context: context}
+	// Links without target open in the target of the <base> element.
+	if link.Target = strings.TrimSpace(getAttribute(a, "target")); link.Target == "" {
+		link.Target = doc.baseTarget
+	}
+	for _, attr := range a.Attr {
+		link.Download = link.Download || attr.Key == "download"
+	}
+	link.Rel = strings.ToLower(strings.Join(strings.Fields(getAttribute(a, "rel")), " "))
+	return link
+}
+
+// unfollowableRels are the link types telling crawlers not to follow links.
+var unfollowableRels = []string{"nofollow", "sponsored", "ugc"}
+
+// FollowableLinks works like Links, but leaves out links crawlers shouldn't
+// follow, which have a rel attribute containing "nofollow", "sponsored" or
+// "ugc".
+func (doc *Document) FollowableLinks(base string) []*util.Link {
+	result := make([]*util.Link, 0, 32)
+	doc.eachLink(base, func(block *html.Node, a *html.Node, link *util.Link) {
+		for _, rel := range unfollowableRels {
+			if link.HasRel(rel) {
+				return
+			}
+		}
+		result = append(result, link)
+	})
+	return result
+}
+
+// DefaultMinHubLinks is the minimum number of links of hub pages suggested
+// for IsHub. Articles rarely link to as many pages outside of navigation,
+// which is removed while parsing.
+const DefaultMinHubLinks = 20
+
+// LinkCount returns the number of links returned by Links.
+func (doc *Document) LinkCount() int {
+	result := 0
+	doc.eachLink("", func(block *html.Node, a *html.Node, link *util.Link) {
+		result++
+	})
+	return result
+}
+
+// UniqueHostCount returns the number of distinct hosts the links returned
+// by Links point to. Links without host, e.g. because the document's URL is
+// unknown, aren't counted.
+func (doc *Document) UniqueHostCount() int {
+	hosts := make(map[string]bool)
+	doc.eachLink("", func(block *html.Node, a *html.Node, link *util.Link) {
+		if u, err := url.Parse(link.URL); err == nil && u.Host != "" {
+			hosts[strings.ToLower(u.Host)] = true
+		}
+	})
+	return len(hosts)
+}
+
+// IsHub returns true if the document looks like a hub page, e.g. a front
+// page or an index of articles, rather than a leaf page holding an article.
+// Hub pages have at least minLinks links and most of their text is link
+// text. IsHub returns false if the Parser skipped counting the link text.
+func (doc *Document) IsHub(minLinks int) bool {
+	return doc.LinkCount() >= minLinks && doc.linkDensity(doc.body) >= 0.5
+}
+
+// A ScoredLink is a link with the likelihood that it points to a full
+// article, ranging from 0 to 1.
+type ScoredLink struct {
+	*util.Link
+	Score float32
+}
+
+// datePath matches dates in URL paths like /2014/03/05/ or /20140305.
+var datePath = util.NewRegex(`/(19|20)\d\d([/-]?(0?[1-9]|1[0-2]))([/-]?\d\d?)?(/|$|[-_])`)
+
+// ScoredLinks works like Links, but scores each link by how likely it
+// points to a full article and returns the links sorted by descending score.
+// Links to articles tend to have deep paths containing dates or slugs and
+// long anchor texts, and they aren't part of navigation, headers, footers
+// or link lists. Category pages have short paths and short anchor texts.
+func (doc *Document) ScoredLinks(base string) []ScoredLink {
+	result := make([]ScoredLink, 0, 32)
+	doc.eachLink(base, func(block *html.Node, a *html.Node, link *util.Link) {
+		score := scoreURL(link.URL)
+		// Anchor texts of articles are headlines.
+		words := len(strings.Fields(link.Text))
+		if words > 8 {
+			score += 0.3
+		} else {
+			score += 0.3 * float32(words) / 8
+		}
+		// Link lists of menus and category pages contain nothing else.
+		if doc.linkDensity(block) < 0.9 || words > 3 {
+			score += 0.1
+		}
+		if inNavigation(a) {
+			score *= 0.5
+		}
+		result = append(result, ScoredLink{Link: link, Score: score})
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result
+}
+
+// scoreURL scores ref by its path, which contributes up to 0.6 to the score
+// of a link.
+func scoreURL(ref string) float32 {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return 0
+	}
+	path := strings.Trim(u.Path, "/")
+	if path == "" {
+		return 0
+	}
+	score := float32(0)
+	segments := strings.Split(path, "/")
+	if len(segments) >= 2 {
+		score += 0.1
+	}
+	if len(segments) >= 3 {
+		score += 0.1
+	}
+	if datePath.In("/" + path) {
+		score += 0.25
+	}
+	// Slugs like storm-hits-the-coast are made from headlines.
+	if last := segments[len(segments)-1]; strings.Count(last, "-")+strings.Count(last, "_") >= 2 {
+		score += 0.15
+	}
+	return score
+}
+
+// inNavigation returns true if n is enclosed by an element holding the
+// navigation, header or footer of a page.
+func inNavigation(n *html.Node) bool {
+	for n = n.Parent; n != nil; n = n.Parent {
+		switch n.DataAtom {
+		case atom.Nav, atom.Header, atom.Footer, atom.Menu:
+			return true
+		}
+		switch getAttribute(n, "role") {
+		case "navigation", "banner", "contentinfo", "menu", "menubar":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package html
+
+import (
+	"fmt"
+	"github.com/slyrz/newscat/util"
+	"strings"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p>Intro with <a href="/intro">a link</a>.</p>
+		<h2>Related articles</h2>
+		<ul>
+			<li><a href="/storm">Storm hits the coast</a></li>
+			<li><a href="mailto:news@example.com">Mail us</a></li>
+		</ul>
+		<h2>Sections</h2>
+		<p><a href="http://example.org/world">World</a> <a href="#top">Top</a></p>`)
+
+	want := []struct {
+		text    string
+		url     string
+		context string
+	}{
+		{"a link", "http://example.com/intro", ""},
+		{"Storm hits the coast", "http://example.com/storm", "Related articles"},
+		{"World", "http://example.org/world", "Sections"},
+		{"Top", "http://example.com/page#top", "Sections"},
+	}
+	links := doc.Links("http://example.com/page")
+	if len(links) != len(want) {
+		t.Fatalf("got %d links, want %d", len(links), len(want))
+	}
+	for i, link := range links {
+		if link.Text != want[i].text || link.URL != want[i].url || link.Context != want[i].context {
+			t.Errorf("got link %+v, want %+v", *link, want[i])
+		}
+	}
+}
+
+func TestHeadingLinks(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<h2>Latest news</h2>
+		<h3><a href="/storm">Storm hits the coast</a></h3>
+		<p><a href="/storm#comments">Comments</a></p>
+		<h3>Power <a href="/outages">outages</a> spread</h3>
+		<p><a href="/map">Map</a></p>`)
+
+	want := []struct {
+		text    string
+		url     string
+		context string
+	}{
+		{"Storm hits the coast", "http://example.com/storm", "Latest news"},
+		{"Comments", "http://example.com/storm#comments", "Latest news"},
+		{"outages", "http://example.com/outages", "Latest news"},
+		{"Map", "http://example.com/map", "Power outages spread"},
+	}
+	links := doc.Links("http://example.com/")
+	if len(links) != len(want) {
+		t.Fatalf("got %d links, want %d", len(links), len(want))
+	}
+	for i, link := range links {
+		if link.Text != want[i].text || link.URL != want[i].url || link.Context != want[i].context {
+			t.Errorf("got link %+v, want %+v", *link, want[i])
+		}
+	}
+	if n := doc.LinkCount(); n != len(want) {
+		t.Errorf("got link count %d, want %d", n, len(want))
+	}
+}
+
+func TestUniqueLinks(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p><a href="/storm">Storm</a></p>
+		<p><a href="/storm/">Storm again</a> <a href="/storm#comments">Comments</a></p>
+		<p><a href="HTTP://EXAMPLE.COM/storm">Shouting</a> <a href="/flood">Flood</a></p>`)
+
+	links := doc.UniqueLinks("http://example.com/")
+	if len(links) != 2 || links[0].Text != "Storm" || links[1].Text != "Flood" {
+		t.Errorf("unexpected links %v", links)
+	}
+	if n := len(doc.Links("http://example.com/")); n != 5 {
+		t.Errorf("got %d links, want 5", n)
+	}
+}
+
+func TestSiteLinks(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p><a href="/storm">Relative</a> <a href="//example.com/flood">Protocol-relative</a></p>
+		<p><a href="https://WWW.example.com/fire">With www</a> <a href="http://example.com:8080/wind">Other port</a></p>
+		<p><a href="http://blog.example.com/">Subdomain</a> <a href="//cdn.example.org/x">Other host</a></p>`)
+
+	texts := func(links []*util.Link) string {
+		result := make([]string, 0)
+		for _, link := range links {
+			result = append(result, link.Text)
+		}
+		return strings.Join(result, ",")
+	}
+	tests := []struct {
+		base     string
+		internal string
+		external string
+	}{
+		{"http://www.example.com/news/", "Relative,Protocol-relative,With www,Other port", "Subdomain,Other host"},
+		// Without base, only relative links are known to be internal.
+		{"", "Relative", "Protocol-relative,With www,Other port,Subdomain,Other host"},
+	}
+	for _, test := range tests {
+		if got := texts(doc.InternalLinks(test.base)); got != test.internal {
+			t.Errorf("%q: got internal links %q, want %q", test.base, got, test.internal)
+		}
+		if got := texts(doc.ExternalLinks(test.base)); got != test.external {
+			t.Errorf("%q: got external links %q, want %q", test.base, got, test.external)
+		}
+	}
+}
+
+func TestFollowableLinks(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p><a href="/storm" rel="bookmark">Storm</a></p>
+		<p><a href="http://shop.example.org/" rel="Sponsored noopener">Ad</a></p>
+		<p><a href="http://blog.example.org/" rel="nofollow">Comment</a> <a href="/ugc" rel="ugc">Forum</a></p>`)
+
+	links := doc.Links("")
+	if len(links) != 4 || links[1].Rel != "sponsored noopener" || !links[1].HasRel("sponsored") || links[0].Rel != "bookmark" {
+		t.Fatalf("unexpected links %v", links)
+	}
+	if links := doc.FollowableLinks(""); len(links) != 1 || links[0].Text != "Storm" {
+		t.Errorf("unexpected followable links %v", links)
+	}
+}
+
+func TestLinkTexts(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p><a href="/text">Text only</a></p>
+		<div><a href="/image"><img src="a.jpg" alt="Storm damage"></a></div>
+		<div><a href="/images"><img src="b.jpg" alt="First"> <img src="c.jpg" alt="Second"></a></div>
+		<div><a href="/mixed"><img src="d.jpg" alt="Ignored"> Mixed</a></div>
+		<h2><a href="/heading"><img src="e.jpg" alt="Logo"></a> News</h2>
+		<div><a href="/empty"><img src="f.jpg"></a></div>`)
+
+	want := []string{
+		"/text Text only",
+		"/image Storm damage",
+		"/images First Second",
+		"/mixed Mixed",
+		"/heading Logo",
+		"/empty ",
+	}
+	links := doc.Links("")
+	if len(links) != len(want) {
+		t.Fatalf("got %d links %v, want %d", len(links), links, len(want))
+	}
+	for i, link := range links {
+		if got := link.URL + " " + link.Text; got != want[i] {
+			t.Errorf("got link %q, want %q", got, want[i])
+		}
+	}
+}
+
+func TestLinkTargets(t *testing.T) {
+	doc := newTestDocument(t, `<base target="_top"><base href="http://example.com/" target="_self">`, `
+		<p><a href="/a" target="_blank">New window</a></p>
+		<p><a href="/b">Base target</a></p>
+		<p><a href="/report.pdf" download>Report</a></p>
+		<p><a href="/c" target=" _parent " download="data.csv">Both</a></p>`)
+
+	want := []struct {
+		target   string
+		download bool
+	}{
+		{"_blank", false},
+		{"_top", false},
+		{"_top", true},
+		{"_parent", true},
+	}
+	links := doc.Links("")
+	if len(links) != len(want) {
+		t.Fatalf("got %d links, want %d", len(links), len(want))
+	}
+	for i, link := range links {
+		if link.Target != want[i].target || link.Download != want[i].download {
+			t.Errorf("link %q has target %q and download %v", link.Text, link.Target, link.Download)
+		}
+	}
+	if links[0].URL != "http://example.com/a" {
+		t.Errorf("got URL %q", links[0].URL)
+	}
+}
+
+func TestScoredLinks(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<div role="navigation"><a href="/world/europe/2014/03/05/storm-in-the-north">Storm in the north</a></div>
+		<p><a href="/world">World</a> <a href="/sports">Sports</a> <a href="/business">Business</a></p>
+		<h2>Latest news</h2>
+		<ul>
+			<li><h3><a href="/world/2014/03/05/storm-hits-the-coast">Storm hits the coast, thousands without power</a></h3></li>
+			<li><a href="/business/markets/banks-raise-interest-rates">Banks raise interest rates for the third time</a></li>
+		</ul>`)
+
+	links := doc.ScoredLinks("http://example.com/")
+	if len(links) != 6 {
+		t.Fatalf("got %d links, want 6", len(links))
+	}
+	want := []string{
+		"http://example.com/world/2014/03/05/storm-hits-the-coast",
+		"http://example.com/business/markets/banks-raise-interest-rates",
+		"http://example.com/world/europe/2014/03/05/storm-in-the-north",
+	}
+	for i, url := range want {
+		if links[i].URL != url {
+			t.Errorf("link %d: got %s (%f), want %s", i, links[i].URL, links[i].Score, url)
+		}
+	}
+	if links[0].Context != "Latest news" {
+		t.Errorf("got context %q", links[0].Context)
+	}
+	for _, link := range links[3:] {
+		if link.Score > 0.2 {
+			t.Errorf("category link %s scored %f", link.URL, link.Score)
+		}
+	}
+	for i := 1; i < len(links); i++ {
+		if links[i-1].Score < links[i].Score {
+			t.Errorf("links not sorted by score")
+		}
+	}
+}
+
+func TestLinkCounts(t *testing.T) {
+	hub := "<h2>Latest news</h2><ul>"
+	for i := 0; i < DefaultMinHubLinks; i++ {
+		host := "example.com"
+		if i%4 == 0 {
+			host = fmt.Sprintf("partner%d.example.org", i%8)
+		}
+		// Teasers link their headlines.
+		if i%2 == 0 {
+			hub += fmt.Sprintf(`<li><h3><a href="http://%s/story/%d">Story number %d</a></h3></li>`, host, i, i)
+		} else {
+			hub += fmt.Sprintf(`<li><a href="http://%s/story/%d">Story number %d</a></li>`, host, i, i)
+		}
+	}
+	hub += "</ul>"
+	leaf := `
+		<h1>Storm hits the coast</h1>
+		<p>A powerful storm swept across the coast on Tuesday, leaving thousands
+		of homes without power, officials said.</p>
+		<p>Crews worked through the night, the <a href="http://example.com/utility">utility</a>
+		said in a statement.</p>
+		<p>Forecasters expect <a href="http://weather.example.org/">calmer weather</a> by Friday.</p>`
+
+	tests := []struct {
+		body  string
+		links int
+		hosts int
+		hub   bool
+	}{
+		{hub, DefaultMinHubLinks, 3, true},
+		{leaf, 2, 2, false},
+	}
+	for i, test := range tests {
+		doc := newTestDocument(t, "", test.body)
+		if got := doc.LinkCount(); got != test.links {
+			t.Errorf("%d: got %d links, want %d", i, got, test.links)
+		}
+		if got := doc.UniqueHostCount(); got != test.hosts {
+			t.Errorf("%d: got %d hosts, want %d", i, got, test.hosts)
+		}
+		if got := doc.IsHub(DefaultMinHubLinks); got != test.hub {
+			t.Errorf("%d: IsHub = %v, want %v", i, got, test.hub)
+		}
+	}
+	// Fewer links than required make any page a leaf.
+	if doc := newTestDocument(t, "", hub); doc.IsHub(DefaultMinHubLinks + 1) {
+		t.Errorf("IsHub with too few links")
+	}
+}
@@ -0,0 +1,605 @@
+package html
+
+import (
+	"errors"
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Errors returned by the metadata accessors.
+var (
+	ErrNoDate = errors.New("no publication date")
+)
+
+// getAttribute returns the value of the attribute key or an empty string
+// if n has no such attribute.
+func getAttribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// getMeta returns the content of the first <meta> element in the document
+// head whose name, property or itemprop attribute matches one of keys.
+// Keys are compared case-insensitively and tried in the given order, so
+// earlier keys take precedence regardless of the order of the elements. If
+// the document doesn't contain any matching element, getMeta returns an
+// empty string.
+func (doc *Document) getMeta(keys ...string) string {
+	for _, key := range keys {
+		if content := doc.getMetaKey(key); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// getMetaKey returns the content of the first <meta> element in the
+// document head whose name, property or itemprop attribute matches key.
+func (doc *Document) getMetaKey(key string) string {
+	result := ""
+	IterateNode(doc.head, func(n *html.Node) int {
+		if n.Type != html.ElementNode || n.DataAtom != atom.Meta {
+			return IterNext
+		}
+		content := strings.TrimSpace(getAttribute(n, "content"))
+		if content == "" {
+			return IterNext
+		}
+		for _, attr := range n.Attr {
+			switch attr.Key {
+			case "name", "property", "itemprop":
+				if strings.EqualFold(attr.Val, key) {
+					result = content
+					return IterStop
+				}
+			}
+		}
+		return IterNext
+	})
+	return result
+}
+
+var (
+	authorNames  = util.NewRegexFromWords("author", "byline")
+	authorPrefix = util.NewRegex(`(?i)^by\s+`)
+)
+
+// cleanAuthor strips whitespace and a leading "By" from the author s.
+func cleanAuthor(s string) string {
+	return strings.TrimSpace(authorPrefix.ReplaceAllString(strings.TrimSpace(s), ""))
+}
+
+// Author returns the name of the article's author. It prefers the author of
+// JSON-LD article data, followed by the author metadata found in the document
+// head, and falls back to the first short byline or microdata / RDFa author
+// property found in the body. Multiple JSON-LD authors are separated by
+// commas. If no author can be found, Author returns an empty string.
+func (doc *Document) Author() string {
+	if article := doc.getJSONLDArticle(); article != nil {
+		if names := getJSONLDNames(article["author"]); len(names) > 0 {
+			return strings.Join(names, ", ")
+		}
+	}
+
+	// The article:author property frequently contains the URL of the author's
+	// profile page instead of a name. Ignore these.
+	for _, key := range []string{"author", "article:author"} {
+		if author := cleanAuthor(doc.getMeta(key)); author != "" && !isURL(author) {
+			return author
+		}
+	}
+
+	// Search the body for rel="author" links and elements having author or
+	// byline classes, microdata itemprops or RDFa properties. Long texts are
+	// most likely an author's biography and not the name, so we skip them.
+	const maxWords = 8
+
+	result := ""
+	IterateNode(doc.body, func(n *html.Node) int {
+		if n.Type != html.ElementNode {
+			return IterNext
+		}
+		match, property := false, false
+		for _, attr := range n.Attr {
+			switch attr.Key {
+			case "rel":
+				match = match || attr.Val == "author"
+			case "class":
+				match = match || authorNames.In(attr.Val)
+			case "itemprop", "property":
+				property = property || authorNames.In(attr.Val)
+			}
+		}
+		if !match && !property {
+			return IterNext
+		}
+		// Microdata and RDFa authors are often items themselves, which
+		// carry the author's name in a name property.
+		value := ""
+		if property {
+			if name := findProperty(n, "name"); name != nil {
+				value = propertyValue(name)
+			} else {
+				value = propertyValue(n)
+			}
+		} else {
+			text := util.NewText()
+			IterateText(n, text.WriteString)
+			value = text.String()
+		}
+		text := util.NewText()
+		text.WriteString(value)
+		if text.Words > maxWords {
+			return IterNext
+		}
+		if author := cleanAuthor(text.String()); author != "" && !isURL(author) {
+			result = author
+			return IterStop
+		}
+		return IterNext
+	})
+	return result
+}
+
+// hasProperty returns true if the microdata itemprop or the RDFa property
+// attribute of n contains name. Prefixes like "schema:" are ignored.
+func hasProperty(n *html.Node, name string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key != "itemprop" && attr.Key != "property" {
+			continue
+		}
+		for _, val := range strings.Fields(attr.Val) {
+			if i := strings.LastIndexByte(val, ':'); i >= 0 {
+				val = val[i+1:]
+			}
+			if strings.EqualFold(val, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findProperty returns the first element below n having the microdata or
+// RDFa property name, or nil.
+func findProperty(n *html.Node, name string) *html.Node {
+	var result *html.Node
+	IterateNode(n, func(c *html.Node) int {
+		if c != n && c.Type == html.ElementNode && hasProperty(c, name) {
+			result = c
+			return IterStop
+		}
+		return IterNext
+	})
+	return result
+}
+
+// propertyValue returns the value of the microdata or RDFa property element
+// n, which is either its content attribute, the datetime attribute of <time>
+// elements or its text.
+func propertyValue(n *html.Node) string {
+	for _, key := range []string{"content", "datetime"} {
+		if val := strings.TrimSpace(getAttribute(n, key)); val != "" {
+			return val
+		}
+	}
+	text := util.NewText()
+	IterateText(n, text.WriteString)
+	return text.String()
+}
+
+// isURL returns true if s looks like an absolute HTTP URL.
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// dateLayouts are the date formats commonly found in article metadata.
+// Layouts without time zone are parsed as UTC.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// parseDate parses s using the first matching layout of dateLayouts.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrNoDate
+}
+
+// PublishedTime returns the publication date of the article. It checks
+// the datePublished of JSON-LD article data, the article:published_time and
+// datePublished metadata first, followed by microdata and RDFa datePublished
+// properties and the datetime attributes of <time> elements in the body.
+// If no parseable date can be found, PublishedTime returns the zero time and
+// ErrNoDate.
+func (doc *Document) PublishedTime() (time.Time, error) {
+	candidates := make([]string, 0, 4)
+	if article := doc.getJSONLDArticle(); article != nil {
+		if val, ok := article["datePublished"].(string); ok {
+			candidates = append(candidates, val)
+		}
+	}
+	if val := doc.getMeta("article:published_time", "datePublished"); val != "" {
+		candidates = append(candidates, val)
+	}
+	// Microdata and RDFa properties are more specific than plain <time>
+	// elements, so they come first.
+	times := make([]string, 0, 4)
+	IterateNode(doc.body, func(n *html.Node) int {
+		if n.Type != html.ElementNode {
+			return IterNext
+		}
+		switch {
+		case hasProperty(n, "datePublished"):
+			candidates = append(candidates, propertyValue(n))
+		case n.DataAtom == atom.Time:
+			if val := getAttribute(n, "datetime"); val != "" {
+				times = append(times, val)
+			}
+		}
+		return IterNext
+	})
+	candidates = append(candidates, times...)
+	for _, val := range candidates {
+		if t, err := parseDate(val); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrNoDate
+}
+
+// Description returns the description of the article found in the document
+// head. It prefers the description metadata over og:description. If neither
+// is present, Description returns an empty string.
+func (doc *Document) Description() string {
+	if val := doc.getMeta("description"); val != "" {
+		return val
+	}
+	return doc.getMeta("og:description")
+}
+
+// Keywords returns the comma-separated keywords metadata of the document
+// head. Empty keywords are left out.
+func (doc *Document) Keywords() []string {
+	result := make([]string, 0)
+	for _, val := range strings.Split(doc.getMeta("keywords"), ",") {
+		if val = strings.TrimSpace(val); val != "" {
+			result = append(result, val)
+		}
+	}
+	return result
+}
+
+// publisherName returns the og:site_name metadata or the name of the
+// JSON-LD article's publisher.
+func (doc *Document) publisherName() string {
+	if name := doc.getMeta("og:site_name"); name != "" {
+		return name
+	}
+	if article := doc.getJSONLDArticle(); article != nil {
+		if names := getJSONLDNames(article["publisher"]); len(names) > 0 {
+			return names[0]
+		}
+	}
+	return ""
+}
+
+// SiteName returns the name of the website. It prefers the og:site_name
+// metadata and the publisher of JSON-LD article data. Otherwise it returns
+// the host of the URL base or the document's URL if base is empty, without
+// a leading "www.". If there's no host either, SiteName returns an empty
+// string.
+func (doc *Document) SiteName(base string) string {
+	if name := doc.publisherName(); name != "" {
+		return name
+	}
+	if base == "" {
+		base = doc.URL
+	}
+	if u, err := url.Parse(base); err == nil {
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return ""
+}
+
+// titleSeparators separate the headline from the site name in titles.
+var titleSeparators = []string{" - ", " | ", " — ", " – ", " :: "}
+
+// normalizeName returns the lower case letters and digits of s, so names
+// like "The Verge" and "theverge" compare equal.
+func normalizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// CleanTitle returns the title without a trailing site name, e.g.
+// "Headline | CNN" becomes "Headline". The site name is only stripped if
+// it matches the og:site_name metadata, the JSON-LD publisher or the host
+// of the document's URL.
+// Otherwise, the separator is considered part of the headline and
+// CleanTitle returns the title unchanged.
+func (doc *Document) CleanTitle() string {
+	return doc.cleanTitle(doc.Title.String())
+}
+
+// cleanTitle strips a trailing site name from title, see CleanTitle.
+func (doc *Document) cleanTitle(title string) string {
+	names := make([]string, 0, 4)
+	if name := normalizeName(doc.publisherName()); name != "" {
+		names = append(names, name)
+	}
+	if u, err := url.Parse(doc.URL); err == nil && u.Hostname() != "" {
+		// Besides the host, accept its labels except the top-level domain,
+		// e.g. "cnn" for edition.cnn.com.
+		host := strings.ToLower(u.Hostname())
+		labels := strings.Split(host, ".")
+		names = append(names, normalizeName(strings.TrimPrefix(host, "www.")))
+		for _, label := range labels[:len(labels)-1] {
+			names = append(names, normalizeName(label))
+		}
+	}
+
+	cut := -1
+	for _, sep := range titleSeparators {
+		i := strings.LastIndex(title, sep)
+		if i <= 0 || i < cut {
+			continue
+		}
+		tail := normalizeName(title[i+len(sep):])
+		for _, name := range names {
+			if tail == name {
+				cut = i
+				break
+			}
+		}
+	}
+	if cut < 0 {
+		return title
+	}
+	return strings.TrimSpace(title[:cut])
+}
+
+// A TitleSource is a source of the document title considered by BestTitle.
+type TitleSource int
+
+const (
+	TitleOpenGraph TitleSource = iota // og:title metadata
+	TitleHeading                      // first <h1> element of the body
+	TitleElement                      // <title> element
+)
+
+// defaultTitleSources is the order of the title sources used if the Parser
+// doesn't set one.
+var defaultTitleSources = []TitleSource{TitleOpenGraph, TitleHeading, TitleElement}
+
+// The limits used by BestTitle. Titles of other sources must share at least
+// titleMinSimilarity of their words with the main heading. Longer titles
+// than titleMaxLength runes are mostly stuffed with keywords.
+const (
+	titleMinSimilarity = 0.5
+	titleMaxLength     = 150
+)
+
+// titleSource returns the title found in source or an empty string.
+func (doc *Document) titleSource(source TitleSource) string {
+	switch source {
+	case TitleOpenGraph:
+		return doc.cleanTitle(doc.getMeta("og:title"))
+	case TitleHeading:
+		for _, chunk := range doc.Chunks {
+			if chunk.Base.DataAtom == atom.H1 {
+				return chunk.Text.String()
+			}
+		}
+	case TitleElement:
+		title := util.NewText()
+		IterateNode(doc.head, func(n *html.Node) int {
+			if n.Type == html.ElementNode && n.DataAtom == atom.Title {
+				IterateText(n, title.WriteString)
+				return IterStop
+			}
+			return IterNext
+		})
+		return doc.cleanTitle(title.String())
+	}
+	return ""
+}
+
+// BestTitle returns the best title of the document. It considers the title
+// sources in the order of the Parser's TitleSources and returns the first
+// title which isn't too long and shares enough words with the main heading,
+// the first <h1> element, if there is one. Titles of the og:title metadata
+// and the <title> element are stripped of trailing site names. If no title
+// fits, BestTitle returns the one most similar to the main heading.
+func (doc *Document) BestTitle() string {
+	sources := defaultTitleSources
+	if doc.parser != nil && len(doc.parser.TitleSources) > 0 {
+		sources = doc.parser.TitleSources
+	}
+
+	heading := util.NewText()
+	heading.WriteString(doc.titleSource(TitleHeading))
+
+	best, bestSimilarity := "", float32(-1.0)
+	for _, source := range sources {
+		title := doc.titleSource(source)
+		if title == "" {
+			continue
+		}
+		similarity := float32(1.0)
+		if source != TitleHeading && heading.Len() > 0 {
+			text := util.NewText()
+			text.WriteString(title)
+			similarity = text.Similarity(heading)
+		}
+		if similarity >= titleMinSimilarity && utf8.RuneCountInString(title) <= titleMaxLength {
+			return title
+		}
+		if similarity > bestSimilarity {
+			best, bestSimilarity = title, similarity
+		}
+	}
+	return best
+}
+
+// OpenGraphAll returns the Open Graph metadata found in the document head.
+// The keys of the result lack the "og:" prefix, e.g. "og:title" becomes
+// "title". The values are in document order.
+func (doc *Document) OpenGraphAll() map[string][]string {
+	result := make(map[string][]string)
+	IterateNode(doc.head, func(n *html.Node) int {
+		if n.Type != html.ElementNode || n.DataAtom != atom.Meta {
+			return IterNext
+		}
+		prop := strings.ToLower(getAttribute(n, "property"))
+		if strings.HasPrefix(prop, "og:") && len(prop) > 3 {
+			key := prop[3:]
+			result[key] = append(result[key], strings.TrimSpace(getAttribute(n, "content")))
+		}
+		return IterNext
+	})
+	return result
+}
+
+// OpenGraph works like OpenGraphAll, but returns only the first value of
+// each key.
+func (doc *Document) OpenGraph() map[string]string {
+	result := make(map[string]string)
+	for key, vals := range doc.OpenGraphAll() {
+		result[key] = vals[0]
+	}
+	return result
+}
+
+// getLinks returns the <link> elements in the document head whose rel
+// attribute contains rel. The link types are compared case-insensitively.
+func (doc *Document) getLinks(rel string) []*html.Node {
+	result := make([]*html.Node, 0)
+	IterateNode(doc.head, func(n *html.Node) int {
+		if n.Type != html.ElementNode || n.DataAtom != atom.Link {
+			return IterNext
+		}
+		for _, val := range strings.Fields(getAttribute(n, "rel")) {
+			if strings.EqualFold(val, rel) {
+				result = append(result, n)
+				break
+			}
+		}
+		return IterNext
+	})
+	return result
+}
+
+// CanonicalURL returns the canonical URL of the article, resolved against
+// the URL base or the document's URL if base is empty. It prefers
+// <link rel="canonical"> over the og:url metadata. If neither is present,
+// CanonicalURL returns an empty string.
+func (doc *Document) CanonicalURL(base string) string {
+	for _, link := range doc.getLinks("canonical") {
+		if href := strings.TrimSpace(getAttribute(link, "href")); href != "" {
+			if result, err := doc.resolveLink(base, href); err == nil {
+				return result
+			}
+		}
+	}
+	if href := doc.getMeta("og:url"); href != "" {
+		if result, err := doc.resolveLink(base, href); err == nil {
+			return result
+		}
+	}
+	return ""
+}
+
+// SitemapURL returns the URL of the website's sitemap, resolved against the
+// URL base or the document's URL if base is empty. It prefers
+// <link rel="sitemap"> and falls back to the conventional /sitemap.xml path
+// on the host of base. If neither is possible, SitemapURL returns an empty
+// string.
+func (doc *Document) SitemapURL(base string) string {
+	for _, link := range doc.getLinks("sitemap") {
+		if href := strings.TrimSpace(getAttribute(link, "href")); href != "" {
+			if result, err := doc.resolveLink(base, href); err == nil {
+				return result
+			}
+		}
+	}
+	return doc.hostURL(base, "/sitemap.xml")
+}
+
+// iconSize returns the area of the largest size listed in the sizes
+// attribute of an icon link. The size "any" denotes a scalable icon and
+// beats every other size.
+func iconSize(sizes string) int {
+	result := 0
+	for _, size := range strings.Fields(strings.ToLower(sizes)) {
+		if size == "any" {
+			return math.MaxInt32
+		}
+		if i := strings.IndexByte(size, 'x'); i > 0 {
+			w, errw := strconv.Atoi(size[:i])
+			h, errh := strconv.Atoi(size[i+1:])
+			if errw == nil && errh == nil && w*h > result {
+				result = w * h
+			}
+		}
+	}
+	return result
+}
+
+// Favicon returns the URL of the website's icon, resolved against the URL
+// base or the document's URL if base is empty. It picks the icon with the
+// largest declared size among the <link rel="icon">, <link rel="shortcut icon">
+// and <link rel="apple-touch-icon"> elements and falls back to the
+// conventional /favicon.ico path on the host of base. If neither is possible,
+// Favicon returns an empty string.
+func (doc *Document) Favicon(base string) string {
+	result, best := "", -1
+	for _, rel := range []string{"icon", "apple-touch-icon"} {
+		for _, link := range doc.getLinks(rel) {
+			// Resolving an empty href results in the page's own URL.
+			raw := strings.TrimSpace(getAttribute(link, "href"))
+			if raw == "" {
+				continue
+			}
+			href, err := doc.resolveLink(base, raw)
+			if err != nil || href == "" {
+				continue
+			}
+			if size := iconSize(getAttribute(link, "sizes")); size > best {
+				result, best = href, size
+			}
+		}
+	}
+	if result != "" {
+		return result
+	}
+	return doc.hostURL(base, "/favicon.ico")
+}
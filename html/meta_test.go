@@ -0,0 +1,372 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestDocument(t *testing.T, head string, body string) *Document {
+	doc, err := NewDocument(strings.NewReader(
+		"<html><head>" + head + "</head><body>" + body + "</body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestAuthor(t *testing.T) {
+	tests := []struct {
+		head   string
+		body   string
+		author string
+	}{
+		{
+			`<meta name="author" content="Jane Doe">`,
+			`<p class="byline">By John Smith</p>`,
+			"Jane Doe",
+		},
+		{
+			`<meta property="article:author" content="https://example.com/jane">`,
+			`<p class="byline">By John Smith</p>`,
+			"John Smith",
+		},
+		{
+			``,
+			`<div class="article-byline">
+				BY   Maria Garcia and Li Wei
+			</div>`,
+			"Maria Garcia and Li Wei",
+		},
+		{
+			``,
+			`<p>Written by <a href="/staff/tom" rel="author">Tom Jones</a></p>`,
+			"Tom Jones",
+		},
+		{
+			``,
+			`<span itemprop="author"><span itemprop="name">Bylined Reporter</span></span>`,
+			"Bylined Reporter",
+		},
+		{
+			``,
+			`<div itemprop="author" itemscope><meta itemprop="name" content="Meta Name"></div>`,
+			"Meta Name",
+		},
+		{
+			``,
+			`<p>By <span property="schema:author">Ann Lee</span></p>`,
+			"Ann Lee",
+		},
+		{
+			``,
+			`<span property="author" content="Ann Lee"><a href="/ann">@annlee</a></span>`,
+			"Ann Lee",
+		},
+		{
+			``,
+			`<div class="author-bio">Tom Jones has been covering politics for
+			more than twenty years and lives in Washington with his family.</div>`,
+			"",
+		},
+	}
+	for _, test := range tests {
+		if author := newTestDocument(t, test.head, test.body).Author(); author != test.author {
+			t.Errorf("Author() = %q, want %q", author, test.author)
+		}
+	}
+}
+
+func TestPublishedTime(t *testing.T) {
+	tests := []struct {
+		head string
+		body string
+		date string
+	}{
+		{
+			`<meta property="article:published_time" content="2014-03-05T10:20:30+01:00">`,
+			`<time datetime="2001-01-01">Jan 1</time>`,
+			"2014-03-05T09:20:30Z",
+		},
+		{
+			`<meta itemprop="datePublished" content="2014-03-05T10:20:30.123Z">`,
+			``,
+			"2014-03-05T10:20:30Z",
+		},
+		{
+			``,
+			`<meta itemprop="datePublished" content="2014-03-05T10:20:30">`,
+			"2014-03-05T10:20:30Z",
+		},
+		{
+			``,
+			`<time datetime="2001-01-01">Jan 1</time><span itemprop="datePublished">2014-03-05</span>`,
+			"2014-03-05T00:00:00Z",
+		},
+		{
+			``,
+			`<span property="datePublished" content="2014-03-05T10:20:30Z">March 5</span>`,
+			"2014-03-05T10:20:30Z",
+		},
+		{
+			``,
+			`<time datetime="2014-03-05">March 5</time>`,
+			"2014-03-05T00:00:00Z",
+		},
+		{
+			``,
+			`<time datetime="yesterday">?</time><time datetime="Wed, 05 Mar 2014 10:20:30 -0500">March 5</time>`,
+			"2014-03-05T15:20:30Z",
+		},
+	}
+	for _, test := range tests {
+		date, err := newTestDocument(t, test.head, test.body).PublishedTime()
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+		if s := date.UTC().Format("2006-01-02T15:04:05Z"); s != test.date {
+			t.Errorf("PublishedTime() = %s, want %s", s, test.date)
+		}
+	}
+
+	if _, err := newTestDocument(t, "", "<p>Hello</p>").PublishedTime(); err != ErrNoDate {
+		t.Errorf("expected ErrNoDate")
+	}
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{`<meta property="og:description" content="Open Graph"><meta name="description" content=" First "><meta name="description" content="Second">`, "First"},
+		{`<meta name="description" content=""><meta property="og:description" content="Open Graph">`, "Open Graph"},
+		{``, ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, "").Description(); got != test.want {
+			t.Errorf("Description() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{`<meta name="keywords" content="storm, coast ,, weather"><meta name="keywords" content="other">`, "storm|coast|weather"},
+		{`<meta name="keywords" content=" , ">`, ""},
+		{``, ""},
+	}
+	for _, test := range tests {
+		keywords := newTestDocument(t, test.head, "").Keywords()
+		if keywords == nil {
+			t.Errorf("Keywords() returned nil")
+		}
+		if got := strings.Join(keywords, "|"); got != test.want {
+			t.Errorf("Keywords() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestOpenGraph(t *testing.T) {
+	doc := newTestDocument(t, `
+		<meta property="og:title" content="Hello World">
+		<meta property="og:type" content="article">
+		<meta property="og:image" content="a.jpg">
+		<meta property="og:image" content="b.jpg">
+		<meta property="article:author" content="Jane Doe">`, "")
+
+	og := doc.OpenGraph()
+	if len(og) != 3 || og["title"] != "Hello World" || og["type"] != "article" || og["image"] != "a.jpg" {
+		t.Errorf("unexpected metadata %v", og)
+	}
+	if images := doc.OpenGraphAll()["image"]; len(images) != 2 || images[1] != "b.jpg" {
+		t.Errorf("unexpected images %v", images)
+	}
+
+	// The parser adds an empty head to documents without head.
+	doc, err := NewDocument(strings.NewReader("<html><body><p>Hello</p></body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if og := doc.OpenGraph(); len(og) != 0 {
+		t.Errorf("unexpected metadata %v", og)
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		head string
+		base string
+		want string
+	}{
+		{`<meta property="og:url" content="http://example.com/og"><link rel="canonical" href="http://example.com/canonical">`, "", "http://example.com/canonical"},
+		{`<meta property="og:url" content="http://example.com/og">`, "", "http://example.com/og"},
+		{`<link rel="Canonical" href="/news/1">`, "http://example.com/news/1?page=2", "http://example.com/news/1"},
+		{`<link rel="alternate" href="/feed">`, "http://example.com/", ""},
+		{`<link rel="canonical" href="javascript:void(0)"><meta property="og:url" content="//example.com/og">`, "https://example.com/", "https://example.com/og"},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, "").CanonicalURL(test.base); got != test.want {
+			t.Errorf("CanonicalURL() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestBaseHref(t *testing.T) {
+	tests := []struct {
+		head string
+		base string
+		want string
+	}{
+		{`<base href="http://cdn.example.com/news/">`, "http://example.com/a/b", "http://cdn.example.com/news/2014/story"},
+		{`<base href="/archive/">`, "http://example.com/a/b", "http://example.com/archive/2014/story"},
+		{`<base target="_blank"><base href="../">`, "http://example.com/a/b/c", "http://example.com/a/2014/story"},
+		{``, "http://example.com/a/b", "http://example.com/a/2014/story"},
+	}
+	for _, test := range tests {
+		doc := newTestDocument(t, test.head+`<link rel="canonical" href="2014/story">`, "")
+		if got := doc.CanonicalURL(test.base); got != test.want {
+			t.Errorf("CanonicalURL() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestSitemapURL(t *testing.T) {
+	tests := []struct {
+		head string
+		base string
+		want string
+	}{
+		{`<link rel="sitemap" type="application/xml" href="/sitemaps/news.xml">`, "http://example.com/a/b", "http://example.com/sitemaps/news.xml"},
+		{`<link rel="Sitemap" href="http://cdn.example.com/map.xml">`, "", "http://cdn.example.com/map.xml"},
+		{`<link rel="alternate" href="/feed">`, "https://example.com/news/1?page=2", "https://example.com/sitemap.xml"},
+		{``, "", ""},
+		{``, "/news/1", ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, "").SitemapURL(test.base); got != test.want {
+			t.Errorf("SitemapURL(%q) = %q, want %q", test.base, got, test.want)
+		}
+	}
+
+	doc := newTestDocument(t, "", "")
+	doc.URL = "http://example.com/story"
+	if got := doc.SitemapURL(""); got != "http://example.com/sitemap.xml" {
+		t.Errorf("SitemapURL() = %q", got)
+	}
+}
+
+func TestFavicon(t *testing.T) {
+	tests := []struct {
+		head string
+		base string
+		want string
+	}{
+		{`<link rel="shortcut icon" href="/favicon.png">`, "http://example.com/a/b", "http://example.com/favicon.png"},
+		{`<link rel="icon" href="small.png" sizes="16x16"><link rel="icon" href="big.png" sizes="16x16 64x64"><link rel="apple-touch-icon" href="touch.png" sizes="57x57">`, "http://example.com/a/b", "http://example.com/a/big.png"},
+		{`<link rel="icon" href="unsized.png"><link rel="apple-touch-icon" href="//cdn.example.com/touch.png" sizes="180x180">`, "https://example.com/", "https://cdn.example.com/touch.png"},
+		{`<link rel="icon" href="a.png" sizes="512x512"><link rel="icon" href="b.svg" sizes="any">`, "http://example.com/", "http://example.com/b.svg"},
+		{`<link rel="icon" href="data:image/png;base64,iVBORw0KGgo=">`, "http://example.com/a/b", "http://example.com/favicon.ico"},
+		{`<link rel="icon" sizes="64x64"><link rel="icon" href=" " sizes="32x32"><link rel="icon" href="small.png" sizes="16x16">`, "http://example.com/a/b", "http://example.com/a/small.png"},
+		{`<link rel="icon">`, "http://example.com/a/b", "http://example.com/favicon.ico"},
+		{``, "", ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, "").Favicon(test.base); got != test.want {
+			t.Errorf("Favicon() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		head string
+		url  string
+		want string
+	}{
+		{`<title>Storm hits the coast - The New York Times</title><meta property="og:site_name" content="The New York Times">`, "", "Storm hits the coast"},
+		{`<title>Storm hits the coast | CNN</title>`, "https://edition.cnn.com/2014/storm", "Storm hits the coast"},
+		{`<title>Storm hits the coast | CNN</title>`, "https://example.com/2014/storm", "Storm hits the coast | CNN"},
+		{`<title>Storm hits the coast — example.com</title>`, "http://example.com/storm", "Storm hits the coast"},
+		{`<title>Politics :: Storm hits the coast :: The Verge</title><meta property="og:site_name" content="theverge">`, "", "Politics :: Storm hits the coast"},
+		{`<title>Spider-Man - A review of the new movie</title><meta property="og:site_name" content="Example">`, "http://example.com/", "Spider-Man - A review of the new movie"},
+		{`<title>Example</title><meta property="og:site_name" content="Example">`, "", "Example"},
+	}
+	for _, test := range tests {
+		doc := newTestDocument(t, test.head, "")
+		doc.URL = test.url
+		if got := doc.CleanTitle(); got != test.want {
+			t.Errorf("CleanTitle() = %q, want %q", got, test.want)
+		}
+	}
+
+	doc := newTestDocument(t, `<title>Headline | CNN</title>`, "")
+	doc.URL = "http://cnn.com/"
+	if doc.CleanTitle(); doc.Title.String() != "Headline | CNN" {
+		t.Errorf("Title was modified")
+	}
+}
+
+func TestSiteName(t *testing.T) {
+	tests := []struct {
+		head string
+		base string
+		want string
+	}{
+		{`<meta property="og:site_name" content=" The Daily News "><script type="application/ld+json">{"@type": "NewsArticle", "publisher": {"@type": "Organization", "name": "Daily News Corp"}}</script>`, "http://example.com/", "The Daily News"},
+		{`<script type="application/ld+json">{"@type": "NewsArticle", "publisher": {"@type": "Organization", "name": "Daily News Corp"}}</script>`, "http://example.com/", "Daily News Corp"},
+		{``, "https://www.Example.com/news/1", "example.com"},
+		{``, "/news/1", ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, "").SiteName(test.base); got != test.want {
+			t.Errorf("SiteName(%q) = %q, want %q", test.base, got, test.want)
+		}
+	}
+
+	// Publisher names count for CleanTitle as well.
+	doc := newTestDocument(t, `<title>Storm hits the coast - Daily News Corp</title>`+
+		`<script type="application/ld+json">{"@type": "NewsArticle", "publisher": {"name": "Daily News Corp"}}</script>`, "")
+	if got := doc.CleanTitle(); got != "Storm hits the coast" {
+		t.Errorf("CleanTitle() = %q", got)
+	}
+}
+
+func TestBestTitle(t *testing.T) {
+	const stuffed = `<title>Storm news, weather news, breaking news, local news, coast news and more | Example</title>`
+	tests := []struct {
+		head string
+		body string
+		want string
+	}{
+		// og:title matches the heading.
+		{`<title>Storm hits the coast - Example</title><meta property="og:title" content="Storm hits the northern coast">`, `<h1>Storm hits the northern coast</h1>`, "Storm hits the northern coast"},
+		// Generic og:title and keyword stuffed title.
+		{stuffed + `<meta property="og:title" content="Example News">`, `<h1>Storm hits the coast</h1>`, "Storm hits the coast"},
+		// The title strips the site name.
+		{`<title>Storm hits the coast | Example</title><meta property="og:site_name" content="Example">`, `<p>Text</p>`, "Storm hits the coast"},
+		// Without heading, the first title source wins.
+		{`<title>Storm hits the coast</title><meta property="og:title" content="A storm hits">`, `<p>Text</p>`, "A storm hits"},
+		{`<title>Storm hits the coast</title>`, `<p>Text</p>`, "Storm hits the coast"},
+		{``, `<p>Text</p>`, ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, test.body).BestTitle(); got != test.want {
+			t.Errorf("BestTitle() = %q, want %q", got, test.want)
+		}
+	}
+
+	p := NewParser()
+	p.TitleSources = []TitleSource{TitleElement, TitleHeading}
+	doc, err := p.Parse(strings.NewReader(`<html><head><title>Storm hits the northern coast</title>` +
+		`<meta property="og:title" content="Storm hits the coast"></head><body><h1>Storm hits the coast</h1></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.BestTitle(); got != "Storm hits the northern coast" {
+		t.Errorf("BestTitle() = %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package html
+
+import (
+	"bytes"
+	"golang.org/x/net/html"
+	"strings"
+)
+
+// A span is a range of bytes in the HTML data.
+type span struct {
+	start int // offset of the first byte
+	end   int // offset after the last byte
+}
+
+// textToken is a text token of the HTML data.
+type textToken struct {
+	text string // the unescaped text
+	span span   // the position of the raw text
+}
+
+// findTextOffsets returns the positions of the text nodes of the parse tree
+// rooted at root in data, which is the HTML data the tree was parsed from.
+//
+// The parser doesn't keep track of positions, so we tokenize the data a
+// second time and remember the position of every text token. Then we walk
+// the text nodes of the parse tree in document order and match them with
+// the text tokens, skipping tokens the parser dropped. Usually a text node
+// matches exactly one token, but the parser merges consecutive text tokens
+// sometimes. Text nodes the parser synthesized or moved don't match any
+// token and are missing in the result.
+func findTextOffsets(data []byte, root *html.Node) map[*html.Node]span {
+	tokens := make([]textToken, 0, 256)
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	for pos := 0; ; {
+		typ := tokenizer.Next()
+		if typ == html.ErrorToken {
+			break
+		}
+		size := len(tokenizer.Raw())
+		if typ == html.TextToken {
+			tokens = append(tokens, textToken{string(tokenizer.Text()), span{pos, pos + size}})
+		}
+		pos += size
+	}
+
+	result := make(map[*html.Node]span)
+	next := 0
+	IterateNode(root, func(n *html.Node) int {
+		if n.Type != html.TextNode {
+			return IterNext
+		}
+		for i := next; i < len(tokens); i++ {
+			if !strings.HasPrefix(n.Data, tokens[i].text) || tokens[i].text == "" {
+				continue
+			}
+			// Consume tokens until their concatenation equals the node's text.
+			rest, j := n.Data[len(tokens[i].text):], i+1
+			for ; rest != "" && j < len(tokens) && strings.HasPrefix(rest, tokens[j].text); j++ {
+				rest = rest[len(tokens[j].text):]
+			}
+			if rest == "" {
+				result[n] = span{tokens[i].span.start, tokens[j-1].span.end}
+				next = j
+				break
+			}
+		}
+		return IterNext
+	})
+	return result
+}
+
+// findChunkSpan returns the position of the text nodes below n in the HTML
+// data or false if it's unknown.
+func (doc *Document) findChunkSpan(n *html.Node) (span, bool) {
+	result, found := span{}, false
+	IterateNode(n, func(c *html.Node) int {
+		if s, ok := doc.offsets[c]; ok {
+			if !found || s.start < result.start {
+				result.start = s.start
+			}
+			if !found || s.end > result.end {
+				result.end = s.end
+			}
+			found = true
+		}
+		return IterNext
+	})
+	return result, found
+}
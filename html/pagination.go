@@ -0,0 +1,154 @@
+package html
+
+import (
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strconv"
+	"strings"
+)
+
+// nextTexts are link texts of "next page" links, in lower case.
+var nextTexts = map[string]bool{
+	"next":          true,
+	"next page":     true,
+	"next »":        true,
+	"next ›":        true,
+	"next >":        true,
+	"next >>":       true,
+	"»":             true,
+	"›":             true,
+	"continue »":    true,
+	"nächste":       true,
+	"weiter":        true,
+	"suivant":       true,
+	"siguiente":     true,
+	"page suivante": true,
+}
+
+// pagerNames matches class and id names of pagination elements. Only whole
+// names count, since names like "page" or "page-wrapper" often belong to
+// the outermost element of the page.
+var pagerNames = util.NewRegex(`(?i)\b(pager|pagination|paging|page-numbers)\b`)
+
+// hasRel returns true if the rel attribute of n contains rel.
+func hasRel(n *html.Node, rel string) bool {
+	for _, val := range strings.Fields(getAttribute(n, "rel")) {
+		if strings.EqualFold(val, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCurrent returns true if n marks the current page of a pagination
+// element, either by aria-current="page" or a "current" or "active" class.
+func isCurrent(n *html.Node) bool {
+	if getAttribute(n, "aria-current") == "page" {
+		return true
+	}
+	for _, class := range strings.Fields(getAttribute(n, "class")) {
+		if class == "current" || class == "active" {
+			return true
+		}
+	}
+	return false
+}
+
+// inPager returns true if n or one of its ancestors has a class or id of a
+// pagination element.
+func inPager(n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n.Type == html.ElementNode && (pagerNames.In(getAttribute(n, "class")) || pagerNames.In(getAttribute(n, "id"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// findNextPage returns the href of the link to the next page of a paginated
+// article. It prefers rel="next" links, followed by links with texts like
+// "Next" or "»". Otherwise, it searches the pagination elements for a link
+// to the page number following the current page, which is marked by
+// aria-current="page" or a "current" / "active" class. The search has to
+// happen before cleaning, which removes the <nav> elements paginators
+// often live in.
+func findNextPage(root *html.Node) string {
+	var byRel, byText string
+	current := 1
+	numbered := make(map[int]string)
+	IterateNode(root, func(n *html.Node) int {
+		if n.Type != html.ElementNode {
+			return IterNext
+		}
+		switch n.DataAtom {
+		case atom.Link, atom.A:
+			href := strings.TrimSpace(getAttribute(n, "href"))
+			if href == "" || href == "#" {
+				return IterNext
+			}
+			if hasRel(n, "next") {
+				byRel = href
+				return IterStop
+			}
+			if n.DataAtom == atom.Link {
+				return IterNext
+			}
+			text := util.NewText()
+			IterateText(n, text.WriteString)
+			label := strings.ToLower(text.String())
+			if label == "" {
+				label = strings.ToLower(strings.TrimSpace(getAttribute(n, "aria-label")))
+			}
+			switch {
+			case nextTexts[label]:
+				if byText == "" {
+					byText = href
+				}
+			case inPager(n):
+				if num, err := strconv.Atoi(label); err == nil {
+					if _, ok := numbered[num]; !ok {
+						numbered[num] = href
+					}
+				}
+			}
+			if getAttribute(n, "aria-current") == "page" {
+				if num, err := strconv.Atoi(label); err == nil {
+					current = num
+				}
+			}
+			return IterSkip
+		}
+		if isCurrent(n) {
+			if inPager(n) {
+				text := util.NewText()
+				IterateText(n, text.WriteString)
+				if num, err := strconv.Atoi(text.String()); err == nil {
+					current = num
+				}
+			}
+		}
+		return IterNext
+	})
+	switch {
+	case byRel != "":
+		return byRel
+	case byText != "":
+		return byText
+	}
+	return numbered[current+1]
+}
+
+// NextPageURL returns the URL of the next page of a paginated article,
+// resolved against the URL base or the document's URL if base is empty.
+// If the document has no next page, NextPageURL returns an empty string.
+func (doc *Document) NextPageURL(base string) string {
+	if doc.nextHref == "" {
+		return ""
+	}
+	result, err := doc.resolveLink(base, doc.nextHref)
+	if err != nil {
+		return ""
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		head string
+		body string
+		want string
+	}{
+		{
+			`<link rel="prev" href="/story?page=1"><link rel="next" href="/story?page=3">`,
+			`<a href="/other">Next</a>`,
+			"http://example.com/story?page=3",
+		},
+		{
+			``,
+			`<p>Text</p><nav><a href="page/1">Previous</a> <a rel="nofollow next" href="page/3">3</a></nav>`,
+			"http://example.com/news/page/3",
+		},
+		{
+			``,
+			`<div class="pager"><a href="?p=1">«</a><a href="?p=3"> Next </a></div>`,
+			"http://example.com/news/story?p=3",
+		},
+		{
+			``,
+			`<a href="#">Next</a><p class="more"><a href="/story/2" aria-label="Next"><span>»</span></a></p>`,
+			"http://example.com/story/2",
+		},
+		{
+			``,
+			`<nav><ul class="pagination">
+				<li><a href="/story/1">1</a></li>
+				<li class="active"><span>2</span></li>
+				<li><a href="/story/3">3</a></li>
+				<li><a href="/story/4">4</a></li>
+			</ul></nav>`,
+			"http://example.com/story/3",
+		},
+		{
+			``,
+			`<div class="paging"><a href="/story/1" aria-current="page">1</a> <a href="/story/2">2</a></div>`,
+			"http://example.com/story/2",
+		},
+		{
+			``,
+			`<p>Read <a href="/comments">2</a> comments</p><a href="javascript:next()">Next</a>`,
+			"",
+		},
+		{
+			``,
+			`<div id="page" class="page-wrapper homepage"><p>Top <a href="/top/1">1</a> <a href="/top/2">2</a></p></div>`,
+			"",
+		},
+		{
+			``,
+			`<ul class="page-numbers">
+				<li class="inactive"><a href="/story/1">1</a></li>
+				<li class="current"><span>2</span></li>
+				<li><a href="/story/3">3</a></li>
+			</ul>`,
+			"http://example.com/story/3",
+		},
+		{
+			``,
+			`<div class="pager"><span class="inactive">5</span> <a href="/story/2">2</a> <a href="/story/6">6</a></div>`,
+			"http://example.com/story/2",
+		},
+	}
+	for _, test := range tests {
+		doc := newTestDocument(t, test.head, test.body)
+		if got := doc.NextPageURL("http://example.com/news/story"); got != test.want {
+			t.Errorf("NextPageURL() = %q, want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package html
+
+import (
+	"context"
+	"fmt"
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+	"io"
+	"strings"
+)
+
+// DefaultMaxDepth is the default MaxDepth of Parsers. Real pages rarely nest
+// elements half as deep, while it stays well below the 512 open elements the
+// HTML parser accepts, so pathological input gets pruned.
+const DefaultMaxDepth = 256
+
+// defaultIgnoreWords is the default list of words that make parsing ignore
+// an element if they appear in its class, id or itemprop attribute.
+var defaultIgnoreWords = []string{
+	"breadcrumb",
+	"byline",
+	"caption",
+	"comment",
+	"community",
+	"credit",
+	"description",
+	"email",
+	"footer",
+	"gallery",
+	"hide",
+	"infotext",
+	"photo",
+	"related",
+	"shares",
+	"social",
+	"story[-_]?bar",
+	"story[-_]?feature",
+}
+
+var defaultIgnoreNames = util.NewRegexFromWords(defaultIgnoreWords...)
+
+// A Parser parses HTML data into Documents. Changing the settings of a
+// Parser affects all Documents it parses afterwards.
+type Parser struct {
+	Charset string // charset of the data; empty to detect it
+
+	// LayoutTableLevel controls which tables are considered layout tables
+	// and removed before parsing the body. A table is removed if fewer than
+	// LayoutTableLevel elements are between it and the <body> element. Deeper
+	// tables are considered data tables and kept. Zero keeps all tables.
+	LayoutTableLevel int
+
+	// TrackOffsets makes the parser record the position of each Chunk's
+	// text in the HTML data. This requires reading all data into memory
+	// before parsing it.
+	TrackOffsets bool
+
+	// Streaming filters the HTML data before parsing it. Comments and the
+	// contents of removed raw text elements, like scripts and styles, never
+	// enter the node tree. This lowers the memory needed to parse large
+	// pages. Streaming has no effect if TrackOffsets is set.
+	Streaming bool
+
+	// SkipLinkText skips counting the text inside and outside of links,
+	// which saves time if only the chunks' texts and classes are needed.
+	// The LinkText of all Chunks is zero then. Documents parsed this way
+	// aren't suitable for extraction.
+	SkipLinkText bool
+
+	// KeepNoscript makes the parser unwrap the contents of <noscript>
+	// elements in the body instead of removing them. Some sites put their
+	// content or lead images there as fallback for browsers without
+	// JavaScript.
+	KeepNoscript bool
+
+	// KeepPreformatted turns each <pre> element into a single Chunk whose
+	// text keeps its whitespace, like the indentation of source code. These
+	// Chunks are marked Preformatted.
+	KeepPreformatted bool
+
+	// MaxDepth limits the nesting of elements. The contents of elements
+	// nested deeper are dropped, which protects the recursive traversals of
+	// the document from pathological input. Documents marked Truncated lost
+	// content this way. Zero disables the limit. Note that the HTML parser
+	// fails on more than 512 open elements, no matter what MaxDepth is.
+	MaxDepth int
+
+	// TitleSources is the order in which Document.BestTitle considers the
+	// sources of the title. If empty, it considers og:title, the first <h1>
+	// and the <title> element, in this order.
+	TitleSources []TitleSource
+
+	// Unexported fields.
+	ignoreWords    []string           // words of ignored class/id/itemprop names
+	ignoreNames    *util.Regex        // regular expression matching ignoreWords or nil
+	removeElements map[atom.Atom]bool // elements removed before parsing the body
+	excludes       []selector         // selectors of elements removed before parsing the body
+}
+
+// NewParser creates a Parser with default settings.
+func NewParser() *Parser {
+	return &Parser{
+		MaxDepth:       DefaultMaxDepth,
+		ignoreWords:    defaultIgnoreWords,
+		ignoreNames:    defaultIgnoreNames,
+		removeElements: removeElements,
+	}
+}
+
+// AddIgnorePattern adds words to the list of ignored names. Elements whose
+// class, id or itemprop attribute matches one of the words are ignored. Words
+// are matched case-insensitively and may be regular expressions. This is
+// useful for sites in other languages, e.g. AddIgnorePattern("kommentar").
+func (p *Parser) AddIgnorePattern(words ...string) {
+	list := make([]string, 0, len(p.ignoreWords)+len(words))
+	list = append(list, p.ignoreWords...)
+	list = append(list, words...)
+	p.SetIgnorePatterns(list...)
+}
+
+// SetIgnorePatterns replaces the list of ignored names by words, including
+// the default words. Calling it without arguments ignores no names at all.
+func (p *Parser) SetIgnorePatterns(words ...string) {
+	p.ignoreWords = words
+	if len(words) > 0 {
+		p.ignoreNames = util.NewRegexFromWords(words...)
+	} else {
+		p.ignoreNames = nil
+	}
+}
+
+// removes returns true if elements of type a are removed before parsing
+// the body.
+func (p *Parser) removes(a atom.Atom) bool {
+	if a == atom.Noscript && p.KeepNoscript {
+		return false
+	}
+	return p.removeElements[a]
+}
+
+// ExcludeSelector adds the elements matching the CSS selector sel to the
+// elements which are removed before parsing the body, e.g. "#comments" or
+// "div.newsletter-signup". Only tags, classes, ids, combinations thereof and
+// comma-separated lists are supported. Other selectors return ErrSelector.
+func (p *Parser) ExcludeSelector(sel string) error {
+	list, err := parseSelectors(sel)
+	if err != nil {
+		return err
+	}
+	// Copy the list first, because it might be shared with other Parsers.
+	p.excludes = append(p.excludes[:len(p.excludes):len(p.excludes)], list...)
+	return nil
+}
+
+// excluded returns true if the element n matches one of the excluded
+// selectors.
+func (p *Parser) excluded(n *html.Node) bool {
+	for i := range p.excludes {
+		if p.excludes[i].match(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Strip adds the elements tags, e.g. "aside", to the elements which are
+// removed before parsing the body. Tags which aren't known HTML elements
+// are ignored.
+func (p *Parser) Strip(tags ...string) {
+	p.setRemoveElements(true, tags)
+}
+
+// Keep removes the elements tags, e.g. "figure", from the elements which
+// are removed before parsing the body. Tags which aren't known HTML elements
+// are ignored.
+func (p *Parser) Keep(tags ...string) {
+	p.setRemoveElements(false, tags)
+}
+
+// setRemoveElements marks tags as removed or kept. It copies the map first,
+// because it might be shared with other Parsers.
+func (p *Parser) setRemoveElements(remove bool, tags []string) {
+	m := make(map[atom.Atom]bool, len(p.removeElements)+len(tags))
+	for a, val := range p.removeElements {
+		m[a] = val
+	}
+	for _, tag := range tags {
+		if a := atom.Lookup([]byte(strings.ToLower(tag))); a != 0 {
+			m[a] = remove
+		}
+	}
+	p.removeElements = m
+}
+
+// Parse parses the HTML data provided through an io.Reader interface.
+func (p *Parser) Parse(r io.Reader) (*Document, error) {
+	return p.ParseContext(context.Background(), r)
+}
+
+// ParseContext works like Parse, but stops parsing once ctx is done. In this
+// case it returns the error of ctx.
+func (p *Parser) ParseContext(ctx context.Context, r io.Reader) (*Document, error) {
+	doc := new(Document)
+	if err := doc.init(ctx, r, p); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ParseFragment parses a fragment of HTML data as content of a <body>
+// element. This allows to extract snippets lacking <html>, <head> and
+// <body> elements, like stored article bodies.
+func (p *Parser) ParseFragment(r io.Reader) (*Document, error) {
+	doc := &Document{fragment: true}
+	if err := doc.init(context.Background(), r, p); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ParseURL fetches the HTML page at url using client and parses it. Unless
+// the Parser has a Charset, the charset declared by the response's
+// Content-Type header is used, if it's known. The Document's URL is set to
+// the URL of the page after following redirects, so relative URLs found in
+// the document resolve against it.
+func (p *Parser) ParseURL(client *util.Client, url string) (*Document, error) {
+	resp, err := client.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Ignore charsets we don't know, the document might still declare a
+	// usable one.
+	parser := *p
+	if label := util.GetCharset(resp.Header.Get("Content-Type")); parser.Charset == "" {
+		if enc, _ := charset.Lookup(label); enc != nil {
+			parser.Charset = label
+		}
+	}
+	doc, err := parser.Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	doc.URL = resp.Request.URL.String()
+	return doc, nil
+}
@@ -0,0 +1,356 @@
+package html
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// chunkTexts returns the comma separated texts of all chunks in doc.
+func chunkTexts(doc *Document) string {
+	result := make([]string, 0)
+	for _, chunk := range doc.Chunks {
+		result = append(result, chunk.Text.String())
+	}
+	return strings.Join(result, ",")
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	const page = `<html><body>
+		<p class="kommentar">Kommentar</p>
+		<p class="comment">Comment</p>
+		<p>Text</p>
+	</body></html>`
+
+	texts := func(p *Parser) string {
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chunkTexts(doc)
+	}
+
+	p := NewParser()
+	if got := texts(p); got != "Kommentar,Text" {
+		t.Errorf("default patterns: got %q", got)
+	}
+	p.AddIgnorePattern("kommentar")
+	if got := texts(p); got != "Text" {
+		t.Errorf("added patterns: got %q", got)
+	}
+	p.SetIgnorePatterns("kommentar")
+	if got := texts(p); got != "Comment,Text" {
+		t.Errorf("replaced patterns: got %q", got)
+	}
+	p.SetIgnorePatterns()
+	if got := texts(p); got != "Kommentar,Comment,Text" {
+		t.Errorf("no patterns: got %q", got)
+	}
+}
+
+func TestExcludeSelector(t *testing.T) {
+	const page = `<html><body>
+		<div class="box newsletter-signup"><p>Sign up</p></div>
+		<div class="newsletter"><p>Newsletter</p></div>
+		<section id="promo"><p>Promo</p></section>
+		<p class="note wide">Wide note</p>
+		<div class="note"><p>Note</p></div>
+		<p>Text</p>
+	</body></html>`
+
+	tests := []struct {
+		selectors []string
+		want      string
+	}{
+		{nil, "Sign up,Newsletter,Promo,Wide note,Note,Text"},
+		{[]string{".newsletter-signup"}, "Newsletter,Promo,Wide note,Note,Text"},
+		{[]string{"#promo"}, "Sign up,Newsletter,Wide note,Note,Text"},
+		{[]string{"section#promo", "div.box.newsletter-signup"}, "Newsletter,Wide note,Note,Text"},
+		{[]string{"p.note"}, "Sign up,Newsletter,Promo,Note,Text"},
+		{[]string{"P.wide.note, .newsletter"}, "Sign up,Promo,Note,Text"},
+		{[]string{"div#promo"}, "Sign up,Newsletter,Promo,Wide note,Note,Text"},
+	}
+	for _, test := range tests {
+		p := NewParser()
+		for _, sel := range test.selectors {
+			if err := p.ExcludeSelector(sel); err != nil {
+				t.Fatal(err)
+			}
+		}
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := chunkTexts(doc); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.selectors, got, test.want)
+		}
+	}
+
+	for _, sel := range []string{"", "div p", "div > p", "a[href]", "p:first-child", ".a,", "#a#b", "p.", "div*"} {
+		if err := NewParser().ExcludeSelector(sel); err != ErrSelector {
+			t.Errorf("%q: expected ErrSelector, got %v", sel, err)
+		}
+	}
+}
+
+func TestStripKeep(t *testing.T) {
+	const page = `<html><body>
+		<figure><img src="a.jpg"><p>Figure</p></figure>
+		<aside><p>Aside</p></aside>
+		<p>Text</p>
+	</body></html>`
+
+	texts := func(p *Parser) string {
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chunkTexts(doc)
+	}
+
+	p := NewParser()
+	if got := texts(p); got != "Aside,Text" {
+		t.Errorf("default elements: got %q", got)
+	}
+	p.Keep("FIGURE", "no-such-element")
+	if got := texts(p); got != "Figure,Aside,Text" {
+		t.Errorf("kept figure: got %q", got)
+	}
+	p.Strip("aside")
+	if got := texts(p); got != "Figure,Text" {
+		t.Errorf("stripped aside: got %q", got)
+	}
+	p.Strip("figure")
+	if got := texts(p); got != "Text" {
+		t.Errorf("stripped figure: got %q", got)
+	}
+
+	// Changes must not leak into other parsers.
+	if got := texts(NewParser()); got != "Aside,Text" {
+		t.Errorf("new parser: got %q", got)
+	}
+}
+
+func TestSkipLinkText(t *testing.T) {
+	const page = `<html><body>
+		<ul class="menu"><li><a href="/a">Menu</a></li></ul>
+		<p class="text">Some <a href="/b">linked</a> text</p>
+	</body></html>`
+
+	want, err := NewParser().Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser()
+	p.SkipLinkText = true
+	got, err := p.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := chunkTexts(got), chunkTexts(want); a != b {
+		t.Errorf("chunks differ: %q, %q", a, b)
+	}
+	for i, chunk := range got.Chunks {
+		if chunk.LinkText != 0 {
+			t.Errorf("chunk %d has link text %f", i, chunk.LinkText)
+		}
+		if strings.Join(chunk.Classes, " ") != strings.Join(want.Chunks[i].Classes, " ") {
+			t.Errorf("chunk %d has classes %v", i, chunk.Classes)
+		}
+	}
+	if a, b := len(got.GetClassStats()), len(want.GetClassStats()); a != b {
+		t.Errorf("got stats for %d classes, want %d", a, b)
+	}
+}
+
+func BenchmarkSkipLinkText(b *testing.B) {
+	page := strings.Repeat(`<div class="teaser"><p>Some <a href="/">linked</a> text.</p><ul><li><a href="/">Link</a></li></ul></div>`, 2000)
+	for _, skip := range []bool{false, true} {
+		b.Run(fmt.Sprintf("skip=%v", skip), func(b *testing.B) {
+			p := NewParser()
+			p.SkipLinkText = skip
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Parse(strings.NewReader(page)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutTableLevel(t *testing.T) {
+	// The layout table is a child of <body>, so there are no elements in
+	// between. The data table has five elements in between.
+	const page = `<html><body>
+		<table><tr><td>Layout</td></tr></table>
+		<div><div><div><div><div>
+			<table><tr><td>Data</td></tr></table>
+		</div></div></div></div></div>
+	</body></html>`
+
+	tests := []struct {
+		level int
+		want  string
+	}{
+		{0, "Layout,Data"},
+		{1, "Data"},
+		{5, "Data"},
+		{6, ""},
+	}
+	for _, test := range tests {
+		p := NewParser()
+		p.LayoutTableLevel = test.level
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := chunkTexts(doc); got != test.want {
+			t.Errorf("level %d: got %q, want %q", test.level, got, test.want)
+		}
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=ISO-8859-1")
+		w.Write([]byte("<html><head><title>Caf\xe9</title></head><body><img src=\"lead.jpg\"></body></html>"))
+	}))
+	defer server.Close()
+
+	doc, err := NewDocumentFromURL(server.URL + "/news/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title.String() != "Café" {
+		t.Errorf("unexpected title %q", doc.Title.String())
+	}
+	if img := doc.TopImage(""); img != server.URL+"/news/lead.jpg" {
+		t.Errorf("unexpected image %q", img)
+	}
+}
+
+func TestParseURLRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/x", http.RedirectHandler("/news/x", http.StatusMovedPermanently))
+	mux.HandleFunc("/news/x", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><img src="lead.jpg"><p><a href="next">Next</a></p></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	doc, err := NewDocumentFromURL(server.URL + "/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.URL != server.URL+"/news/x" {
+		t.Errorf("unexpected URL %q", doc.URL)
+	}
+	if img := doc.TopImage(""); img != server.URL+"/news/lead.jpg" {
+		t.Errorf("unexpected image %q", img)
+	}
+	if links := doc.Links(""); len(links) != 1 || links[0].URL != server.URL+"/news/next" {
+		t.Errorf("unexpected links %v", links)
+	}
+}
+
+func TestKeepNoscript(t *testing.T) {
+	const page = `<html><head><noscript><style>.lazy { display: none; }</style></noscript></head><body>
+		<div class="lead"><img class="lazy" src="data:image/gif;base64,R0lGODlhAQABAAAAACw="><noscript><img src="lead.jpg" alt="Lead image"></noscript></div>
+		<noscript><p>Fallback text</p></noscript>
+		<p>Article text</p>
+	</body></html>`
+
+	doc, err := NewParser().Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Images) != 0 || chunkTexts(doc) != "Article text" {
+		t.Errorf("noscript wasn't removed: %d images, chunks %q", len(doc.Images), chunkTexts(doc))
+	}
+
+	for _, mode := range []string{"default", "streaming", "offsets"} {
+		p := NewParser()
+		p.KeepNoscript = true
+		p.Streaming = mode == "streaming"
+		p.TrackOffsets = mode == "offsets"
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Images) != 1 || doc.Images[0].URL != "lead.jpg" || doc.Images[0].Alt != "Lead image" {
+			t.Errorf("%s: image wasn't recovered: %v", mode, doc.Images)
+		}
+		if got := chunkTexts(doc); got != "Fallback text,Article text" {
+			t.Errorf("%s: got chunks %q", mode, got)
+		}
+	}
+}
+
+func TestKeepNoscriptHead(t *testing.T) {
+	const page = `<html><head>
+		<noscript><img src="https://example.com/pixel.gif" width="1" height="1"></noscript>
+		<title>Storm hits the coast</title>
+		<meta property="og:site_name" content="Coast News">
+		<meta name="author" content="Jane Doe">
+	</head><body><p>Article text</p></body></html>`
+
+	for _, keep := range []bool{false, true} {
+		p := NewParser()
+		p.KeepNoscript = keep
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := doc.Title.String(); got != "Storm hits the coast" {
+			t.Errorf("KeepNoscript %v: got title %q", keep, got)
+		}
+		if got := doc.SiteName(""); got != "Coast News" {
+			t.Errorf("KeepNoscript %v: got site name %q", keep, got)
+		}
+		if got := doc.Author(); got != "Jane Doe" {
+			t.Errorf("KeepNoscript %v: got author %q", keep, got)
+		}
+		if got := chunkTexts(doc); got != "Article text" {
+			t.Errorf("KeepNoscript %v: got chunks %q", keep, got)
+		}
+	}
+}
+
+func TestKeepPreformatted(t *testing.T) {
+	const code = "func main() {\n\tif true {\n\t\tfmt.Println(\"a  b\")\n\t}\n}"
+	const page = "<html><body><p>Some   code:</p><pre>\n<code>" + code + "</code>\n</pre></body></html>"
+
+	doc, err := NewParser().Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Chunks) != 2 || doc.Chunks[1].Preformatted {
+		t.Errorf("unexpected preformatted chunks %q", chunkTexts(doc))
+	}
+
+	for _, mode := range []string{"default", "offsets"} {
+		p := NewParser()
+		p.KeepPreformatted = true
+		p.TrackOffsets = mode == "offsets"
+		doc, err := p.Parse(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Chunks) != 2 {
+			t.Fatalf("%s: got chunks %q", mode, chunkTexts(doc))
+		}
+		if chunk := doc.Chunks[0]; chunk.Preformatted || chunk.Text.String() != "Some code:" {
+			t.Errorf("%s: unexpected chunk %q", mode, chunk.Text)
+		}
+		chunk := doc.Chunks[1]
+		if !chunk.Preformatted || chunk.Text.String() != code || chunk.Block.Data != "pre" {
+			t.Errorf("%s: got preformatted chunk %q", mode, chunk.Text)
+		}
+		if chunk.Text.Words == 0 {
+			t.Errorf("%s: words weren't counted", mode)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package html
+
+import (
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"sort"
+	"strings"
+)
+
+// breadcrumbNames matches class and id names and ARIA labels of breadcrumb
+// navigations.
+var breadcrumbNames = util.NewRegex(`(?i)bread[-_]?crumb`)
+
+// homeNames are names of the first breadcrumb, which links to the front
+// page, in lower case.
+var homeNames = map[string]bool{
+	"home":       true,
+	"start":      true,
+	"startseite": true,
+	"accueil":    true,
+	"inicio":     true,
+	"portada":    true,
+}
+
+// findBreadcrumbs returns the link texts of the first breadcrumb navigation
+// below root. The search has to happen before cleaning, which removes
+// <nav> elements and elements with breadcrumb class names.
+func findBreadcrumbs(root *html.Node) []string {
+	result := make([]string, 0)
+	IterateNode(root, func(n *html.Node) int {
+		if n.Type != html.ElementNode {
+			return IterNext
+		}
+		if !breadcrumbNames.In(getAttribute(n, "aria-label")) &&
+			!breadcrumbNames.In(getAttribute(n, "class")) &&
+			!breadcrumbNames.In(getAttribute(n, "id")) {
+			return IterNext
+		}
+		IterateNode(n, func(c *html.Node) int {
+			if c.Type == html.ElementNode && c.DataAtom == atom.A {
+				text := util.NewText()
+				IterateText(c, text.WriteString)
+				if text.Len() > 0 {
+					result = append(result, text.String())
+				}
+				return IterSkip
+			}
+			return IterNext
+		})
+		if len(result) > 0 {
+			return IterStop
+		}
+		return IterSkip
+	})
+	return result
+}
+
+// jsonLDBreadcrumbs returns the names of the items of the first JSON-LD
+// BreadcrumbList, ordered by their positions.
+func (doc *Document) jsonLDBreadcrumbs() []string {
+	type crumb struct {
+		position float64
+		name     string
+	}
+	for _, v := range doc.jsonLD {
+		if v["@type"] != "BreadcrumbList" {
+			continue
+		}
+		items, _ := v["itemListElement"].([]interface{})
+		crumbs := make([]crumb, 0, len(items))
+		for _, item := range items {
+			item, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			position, _ := item["position"].(float64)
+			names := getJSONLDNames(item["name"])
+			if len(names) == 0 {
+				names = getJSONLDNames(item["item"])
+			}
+			if len(names) > 0 {
+				crumbs = append(crumbs, crumb{position, names[0]})
+			}
+		}
+		sort.SliceStable(crumbs, func(i, j int) bool {
+			return crumbs[i].position < crumbs[j].position
+		})
+		result := make([]string, len(crumbs))
+		for i, c := range crumbs {
+			result[i] = c.name
+		}
+		return result
+	}
+	return nil
+}
+
+// Section returns the section or category of the article, e.g. "Politics".
+// It prefers the article:section metadata and the articleSection of JSON-LD
+// article data. Otherwise it picks the last breadcrumb of a JSON-LD
+// BreadcrumbList or a breadcrumb navigation which neither links to the
+// front page nor names the article itself. If the document has no section,
+// Section returns an empty string.
+func (doc *Document) Section() string {
+	if val := doc.getMeta("article:section"); val != "" {
+		return val
+	}
+	if article := doc.getJSONLDArticle(); article != nil {
+		if names := getJSONLDNames(article["articleSection"]); len(names) > 0 {
+			return names[0]
+		}
+	}
+	title := normalizeName(doc.Title.String())
+	cleanTitle := normalizeName(doc.CleanTitle())
+	for _, crumbs := range [][]string{doc.jsonLDBreadcrumbs(), doc.crumbs} {
+		for i := len(crumbs) - 1; i >= 0; i-- {
+			name := normalizeName(crumbs[i])
+			if name == "" || name == title || name == cleanTitle || homeNames[strings.ToLower(crumbs[i])] {
+				continue
+			}
+			return crumbs[i]
+		}
+	}
+	return ""
+}
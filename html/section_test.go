@@ -0,0 +1,38 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestSection(t *testing.T) {
+	const breadcrumbList = `<script type="application/ld+json">{"@context": "https://schema.org", "@type": "BreadcrumbList", "itemListElement": [
+		{"@type": "ListItem", "position": 3, "name": "Storm hits the coast"},
+		{"@type": "ListItem", "position": 1, "name": "Home", "item": "https://example.com/"},
+		{"@type": "ListItem", "position": 2, "item": {"@id": "https://example.com/weather", "name": "Weather"}}]}</script>`
+
+	tests := []struct {
+		head string
+		body string
+		want string
+	}{
+		{`<meta property="article:section" content="Politics">` + breadcrumbList, ``, "Politics"},
+		{`<script type="application/ld+json">{"@type": "NewsArticle", "articleSection": ["Science", "Space"]}</script>`, ``, "Science"},
+		{`<title>Storm hits the coast</title>` + breadcrumbList, ``, "Weather"},
+		{`<title>Storm hits the coast | Example</title>`, `
+			<nav aria-label="Breadcrumb"><ol>
+				<li><a href="/">Home</a></li>
+				<li><a href="/world">World</a></li>
+				<li><a href="/world/europe">Europe</a></li>
+				<li aria-current="page">Storm hits the coast</li>
+			</ol></nav>
+			<p>Text</p>`, "Europe"},
+		{`<title>Storm hits the coast</title>`, `
+			<div class="breadcrumbs"><a href="/">Startseite</a> &gt; <a href="/storm">Storm hits the coast</a></div>`, ""},
+		{``, `<nav><a href="/">Home</a><a href="/world">World</a></nav>`, ""},
+	}
+	for _, test := range tests {
+		if got := newTestDocument(t, test.head, test.body).Section(); got != test.want {
+			t.Errorf("Section() = %q, want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package html
+
+import (
+	"errors"
+	"golang.org/x/net/html"
+	"strings"
+)
+
+var (
+	ErrSelector = errors.New("unsupported selector")
+)
+
+// A selector is a simple CSS selector like "div", ".newsletter-signup",
+// "#comments" or "aside.ad.wide". Empty fields match any element.
+type selector struct {
+	tag     string   // lower case element name
+	id      string   // value of the id attribute
+	classes []string // classes the element must have
+}
+
+// isSelectorName returns true if c may appear in tag, class and id names.
+func isSelectorName(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// parseSelectors parses a comma-separated list of simple selectors. It
+// returns ErrSelector for combinators, attribute selectors, pseudo-classes
+// and other syntax beyond tags, classes and ids.
+func parseSelectors(s string) ([]selector, error) {
+	result := make([]selector, 0, 1)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, ErrSelector
+		}
+		sel := selector{}
+		for i := 0; i < len(part); {
+			kind := byte(0)
+			if part[i] == '.' || part[i] == '#' {
+				kind, i = part[i], i+1
+			}
+			j := i
+			for j < len(part) && isSelectorName(part[j]) {
+				j++
+			}
+			name := part[i:j]
+			switch {
+			case name == "":
+				return nil, ErrSelector
+			case kind == '.':
+				sel.classes = append(sel.classes, name)
+			case kind == '#' && sel.id == "":
+				sel.id = name
+			case kind == 0 && i == 0:
+				sel.tag = strings.ToLower(name)
+			default:
+				return nil, ErrSelector
+			}
+			i = j
+		}
+		result = append(result, sel)
+	}
+	return result, nil
+}
+
+// match returns true if the element n matches the selector.
+func (sel *selector) match(n *html.Node) bool {
+	if n.Type != html.ElementNode || (sel.tag != "" && sel.tag != n.Data) {
+		return false
+	}
+	if sel.id != "" && getAttribute(n, "id") != sel.id {
+		return false
+	}
+	if len(sel.classes) > 0 {
+		classes := strings.Fields(getAttribute(n, "class"))
+		for _, want := range sel.classes {
+			found := false
+			for _, class := range classes {
+				if found = class == want; found {
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package html
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"strings"
+)
+
+// rawTextElements are the elements whose contents the tokenizer returns
+// as a single text token.
+var rawTextElements = map[atom.Atom]bool{
+	atom.Iframe:   true,
+	atom.Noembed:  true,
+	atom.Noframes: true,
+	atom.Noscript: true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Textarea: true,
+	atom.Xmp:      true,
+}
+
+// streamFilter is an io.Reader that passes HTML data through a tokenizer and
+// drops the parts which the Document would throw away anyway: comments and
+// the contents of raw text elements like <script> or <style>, if they are
+// removed before parsing the body. JSON-LD scripts are kept. The remaining
+// tokens are passed through unmodified.
+//
+// Pages often embed megabytes of scripts and styles. Filtering them before
+// they reach html.Parse keeps them out of the node tree and bounds the
+// memory needed for them to a single token.
+type streamFilter struct {
+	z      *html.Tokenizer
+	remove func(atom.Atom) bool
+	buf    []byte
+	drop   bool  // true if the current raw text gets dropped
+	err    error // error of the tokenizer
+}
+
+// newStreamFilter creates a streamFilter reading from r. The contents of raw
+// text elements are dropped if remove returns true for them.
+func newStreamFilter(r io.Reader, remove func(atom.Atom) bool) *streamFilter {
+	return &streamFilter{z: html.NewTokenizer(r), remove: remove}
+}
+
+func (sf *streamFilter) Read(p []byte) (int, error) {
+	for len(sf.buf) == 0 {
+		if sf.err != nil {
+			return 0, sf.err
+		}
+		sf.next()
+	}
+	n := copy(p, sf.buf)
+	sf.buf = sf.buf[n:]
+	return n, nil
+}
+
+// next reads the next token into the buffer, unless it gets dropped.
+func (sf *streamFilter) next() {
+	tt := sf.z.Next()
+	drop := sf.drop
+	sf.drop = false
+	switch tt {
+	case html.ErrorToken:
+		sf.err = sf.z.Err()
+		return
+	case html.CommentToken:
+		return
+	case html.TextToken:
+		if drop {
+			return
+		}
+	case html.StartTagToken:
+		// The tokenizer returns the contents of raw text elements as single
+		// text token following the start tag.
+		sf.buf = append(sf.buf[:0], sf.z.Raw()...)
+		name, hasAttr := sf.z.TagName()
+		a := atom.Lookup(name)
+		if !rawTextElements[a] || !sf.remove(a) {
+			return
+		}
+		sf.drop = true
+		for a == atom.Script && hasAttr {
+			var key, val []byte
+			key, val, hasAttr = sf.z.TagAttr()
+			if string(key) == "type" && strings.EqualFold(strings.TrimSpace(string(val)), "application/ld+json") {
+				sf.drop = false
+			}
+		}
+		return
+	}
+	sf.buf = append(sf.buf[:0], sf.z.Raw()...)
+}
@@ -0,0 +1,97 @@
+package html
+
+import (
+	"github.com/slyrz/newscat/util"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strconv"
+)
+
+// maxSpan limits the rowspan and colspan attributes of table cells.
+const maxSpan = 100
+
+// getSpan returns the value of the span attribute key of the table cell n.
+// Missing and invalid values count as 1.
+func getSpan(n *html.Node, key string) int {
+	span, err := strconv.Atoi(getAttribute(n, key))
+	switch {
+	case err != nil || span < 1:
+		return 1
+	case span > maxSpan:
+		return maxSpan
+	}
+	return span
+}
+
+// getRows returns the <tr> elements of table, leaving out the rows of
+// nested tables.
+func getRows(table *html.Node) []*html.Node {
+	result := make([]*html.Node, 0)
+	IterateNode(table, func(n *html.Node) int {
+		switch {
+		case n.Type != html.ElementNode || n == table:
+			return IterNext
+		case n.DataAtom == atom.Table:
+			return IterSkip
+		case n.DataAtom == atom.Tr:
+			result = append(result, n)
+			return IterSkip
+		}
+		return IterNext
+	})
+	return result
+}
+
+// parseTable returns the text of the table's cells row by row. Cells
+// spanning multiple rows or columns are repeated in each of them.
+func parseTable(table *html.Node) [][]string {
+	type pending struct {
+		text string
+		rows int // number of rows left to fill
+	}
+	result := make([][]string, 0)
+	spans := make(map[int]*pending)
+	for _, tr := range getRows(table) {
+		row := make([]string, 0)
+		// fill adds the cells spanning down from previous rows.
+		fill := func() {
+			for p := spans[len(row)]; p != nil && p.rows > 0; p = spans[len(row)] {
+				row = append(row, p.text)
+				p.rows--
+			}
+		}
+		for td := tr.FirstChild; td != nil; td = td.NextSibling {
+			if td.Type != html.ElementNode || (td.DataAtom != atom.Td && td.DataAtom != atom.Th) {
+				continue
+			}
+			fill()
+			text := util.NewText()
+			IterateText(td, text.WriteString)
+			rowspan := getSpan(td, "rowspan")
+			for i := getSpan(td, "colspan"); i > 0; i-- {
+				if rowspan > 1 {
+					spans[len(row)] = &pending{text.String(), rowspan - 1}
+				}
+				row = append(row, text.String())
+			}
+		}
+		fill()
+		result = append(result, row)
+	}
+	return result
+}
+
+// Tables returns the contents of the tables in the document body which
+// survived cleaning, e.g. data tables. Each table is a list of rows, each
+// row a list of cell texts. Cells spanning multiple rows or columns are
+// repeated. Tables nested inside of other tables are returned separately.
+func (doc *Document) Tables() [][][]string {
+	result := make([][][]string, 0)
+	IterateNode(doc.body, func(n *html.Node) int {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Table {
+			result = append(result, parseTable(n))
+		}
+		return IterNext
+	})
+	return result
+}
@@ -0,0 +1,30 @@
+package html
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTables(t *testing.T) {
+	doc := newTestDocument(t, "", `
+		<p>Results of the season.</p>
+		<table>
+			<thead><tr><th>Team</th><th colspan="2">Score</th></tr></thead>
+			<tbody>
+				<tr><td rowspan="2">Lions</td><td>3</td><td>1</td></tr>
+				<tr><td>2</td><td>
+					<table><tr><td>nested</td></tr></table>
+				</td></tr>
+				<tr><td>Tigers</td><td rowspan="x">0</td><td> 4 </td></tr>
+			</tbody>
+		</table>`)
+
+	want := `[[[Team Score Score] [Lions 3 1] [Lions 2 nested] [Tigers 0 4]] [[nested]]]`
+	if got := fmt.Sprint(doc.Tables()); got != want {
+		t.Errorf("Tables() = %s, want %s", got, want)
+	}
+
+	if tables := newTestDocument(t, "", "<p>No tables</p>").Tables(); len(tables) != 0 {
+		t.Errorf("unexpected tables %v", tables)
+	}
+}
@@ -0,0 +1,86 @@
+package html
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Errors returned when resolving links.
+var (
+	ErrNotNavigable = errors.New("URL scheme isn't navigable")
+)
+
+// pseudoSchemes are URL schemes which don't point to documents a browser
+// could navigate to.
+var pseudoSchemes = map[string]bool{
+	"data":       true,
+	"javascript": true,
+	"mailto":     true,
+}
+
+// resolveURL resolves the possibly relative URL ref against the URL base.
+// If base is empty or either URL can't be parsed, ref is returned as is.
+func resolveURL(base string, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if base == "" {
+		return ref
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// resolveURL resolves ref against the URL base. If base is empty, it uses
+// the URL the document was fetched from instead. Like browsers, it honors
+// the document's <base> element, which is resolved against base as well,
+// because it might be relative.
+func (doc *Document) resolveURL(base string, ref string) string {
+	if base == "" {
+		base = doc.URL
+	}
+	if doc.baseHref != "" {
+		base = resolveURL(base, doc.baseHref)
+	}
+	return resolveURL(base, ref)
+}
+
+// resolveLink works like resolveURL, but returns ErrNotNavigable if ref uses
+// one of the pseudoSchemes. Protocol-relative references like
+// //cdn.example.com/x inherit the scheme of base.
+func (doc *Document) resolveLink(base string, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if i := strings.IndexByte(ref, ':'); i > 0 && pseudoSchemes[strings.ToLower(ref[:i])] {
+		return "", ErrNotNavigable
+	}
+	return doc.resolveURL(base, ref), nil
+}
+
+// siteHost returns the lower case host name of the URL s without "www."
+// prefix or an empty string if s has no host.
+func siteHost(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// hostURL returns the absolute path on the host of the URL base or the
+// document's URL if base is empty. If base has no host, hostURL returns an
+// empty string.
+func (doc *Document) hostURL(base string, path string) string {
+	if base == "" {
+		base = doc.URL
+	}
+	if u, err := url.Parse(base); err == nil && u.IsAbs() && u.Host != "" {
+		return resolveURL(base, path)
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package html
+
+import (
+	"testing"
+)
+
+func TestResolveLink(t *testing.T) {
+	doc := newTestDocument(t, "", "")
+	tests := []struct {
+		base string
+		ref  string
+		want string
+		err  error
+	}{
+		{"https://example.com/a/b", "//cdn.example.com/x", "https://cdn.example.com/x", nil},
+		{"http://example.com/a/b", "//cdn.example.com/x", "http://cdn.example.com/x", nil},
+		{"http://example.com/a/b", "c?d=1", "http://example.com/a/c?d=1", nil},
+		{"http://example.com/a/b", "data:image/png;base64,iVBORw0KGgo=", "", ErrNotNavigable},
+		{"http://example.com/a/b", " JavaScript:void(0)", "", ErrNotNavigable},
+		{"http://example.com/a/b", "mailto:editor@example.com", "", ErrNotNavigable},
+		{"http://example.com/a/b", "/2014/mailto:story", "http://example.com/2014/mailto:story", nil},
+	}
+	for _, test := range tests {
+		got, err := doc.resolveLink(test.base, test.ref)
+		if got != test.want || err != test.err {
+			t.Errorf("resolveLink(%q, %q) = %q, %v, want %q, %v", test.base, test.ref, got, err, test.want, test.err)
+		}
+	}
+}
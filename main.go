@@ -1,44 +1,252 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"github.com/slyrz/newscat/html"
 	"github.com/slyrz/newscat/model"
 	"github.com/slyrz/newscat/util"
+	"io"
 	"os"
+	"strings"
 )
 
-var highlight = util.IsTerminal(os.Stdout)
+var (
+	format      = flag.String("format", "text", "output format: text, json, markdown, html or reader")
+	timeout     = flag.Duration("timeout", util.DefaultTimeout, "time limit of HTTP requests")
+	userAgent   = flag.String("user-agent", util.DefaultUserAgent, "User-Agent header of HTTP requests")
+	concurrency = flag.Int("concurrency", 4, "number of inputs processed in parallel")
+	debug       = flag.Bool("debug", false, "print the scored clusters instead of the article")
+	extract     = flag.String("extract", "article", "what to extract: article or links")
+	linkText    = flag.Bool("link-text", true, "print the anchor texts of links after their URLs")
+	keepPre     = flag.Bool("keep-pre", false, "keep the whitespace of preformatted text, like source code")
+	highlight   = util.IsTerminal(os.Stdout)
+)
+
+func printArticle(w io.Writer, article *util.Article) {
+	var err error
+	if highlight {
+		_, err = article.WriteHighlighted(w)
+	} else {
+		_, err = article.WriteTo(w)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// printLinks prints one link per line, the URL followed by a tab and the
+// anchor text unless -link-text is false.
+func printLinks(w io.Writer, links []*util.Link) {
+	bw := bufio.NewWriter(w)
+	for _, link := range links {
+		if *linkText {
+			fmt.Fprintf(bw, "%s\t%s\n", link.URL, link.Text)
+		} else {
+			fmt.Fprintln(bw, link.URL)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// jsonArticle is the JSON representation of an extracted article.
+type jsonArticle struct {
+	Origin   string   `json:"origin,omitempty"`
+	Title    string   `json:"title"`
+	Text     []string `json:"text"`
+	Headings []string `json:"headings"`
+}
 
-func printArticle(article *util.Article) {
-	pre, pos := "", ""
+func newJSONArticle(origin string, article *util.Article) *jsonArticle {
+	result := &jsonArticle{
+		Origin:   origin,
+		Title:    article.Title,
+		Text:     make([]string, 0),
+		Headings: make([]string, 0),
+	}
 	for _, text := range article.Text {
-		if highlight {
-			switch text.(type) {
-			case util.Heading:
-				pre, pos = "\x1b[1m", "\x1b[0m"
-			case util.Paragraph:
-				pre, pos = "", ""
+		switch text := text.(type) {
+		case util.Heading:
+			result.Headings = append(result.Headings, text.Text)
+		case util.Paragraph:
+			result.Text = append(result.Text, string(text))
+		case util.Quote:
+			result.Text = append(result.Text, string(text))
+		case util.ListItem:
+			result.Text = append(result.Text, string(text))
+		case util.Preformatted:
+			result.Text = append(result.Text, string(text))
+		case util.LinkedParagraph:
+			result.Text = append(result.Text, text.Text)
+		}
+	}
+	return result
+}
+
+// printJSON prints the articles as JSON array. The encoder replaces invalid
+// UTF-8 sequences, so the output is valid UTF-8 no matter what the input was.
+func printJSON(articles []*jsonArticle) {
+	if err := json.NewEncoder(os.Stdout).Encode(articles); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// result is the outcome of processing a single input.
+type result struct {
+	Origin  string
+	Article *util.Article // nil if nothing was extracted
+	Err     error         // error opening or parsing the input
+	Debug   string        // scored clusters if -debug is set
+	Links   []*util.Link  // links if -extract links is set
+}
+
+// openDocument parses the file or URL arg. URLs are fetched using client.
+// The argument "-" denotes standard input. It returns the origin of the
+// document along with the document.
+func openDocument(arg string, client *util.Client) (string, *html.Document, error) {
+	parser := html.NewParser()
+	parser.KeepPreformatted = *keepPre
+	if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		document, err := parser.ParseURL(client, arg)
+		return arg, document, err
+	}
+	input, err := util.OpenInput(arg, client)
+	if err != nil {
+		return arg, nil, err
+	}
+	defer input.Data.Close()
+	document, err := parser.Parse(input.Data)
+	if err != nil {
+		return input.Origin, nil, fmt.Errorf("%s: %w", arg, err)
+	}
+	return input.Origin, document, nil
+}
+
+// processInput opens the input arg and extracts its article using ext.
+// Inputs which can't be fetched or parsed result in an error, inputs
+// without article in an empty result.
+func processInput(arg string, client *util.Client, ext *model.Extractor) result {
+	origin, document, err := openDocument(arg, client)
+	if err != nil {
+		return result{Origin: origin, Err: err}
+	}
+	switch {
+	case *extract == "links":
+		return result{Origin: origin, Links: document.Links("")}
+	case *debug:
+		var buf bytes.Buffer
+		if err := ext.WriteDebug(&buf, document); err == nil {
+			return result{Origin: origin, Debug: buf.String()}
+		}
+	default:
+		if article, err := ext.Extract(document); err == nil {
+			return result{Origin: origin, Article: article}
+		}
+	}
+	return result{Origin: origin}
+}
+
+// processInputs processes the inputs args using the given number of
+// concurrent workers. It calls emit for every input in the order of args,
+// no matter in which order processing finishes.
+func processInputs(args []string, client *util.Client, workers int, emit func(result)) {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]chan result, len(args))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		go func() {
+			// Extractors store state, so each worker needs its own.
+			ext := model.NewExtractor()
+			switch *format {
+			case "markdown", "html", "reader":
+				ext.KeepInlineLinks = true
+				ext.KeepEmphasis = true
+				ext.KeepStructure = true
+			}
+			for i := range jobs {
+				results[i] <- processInput(args[i], client, ext)
 			}
+		}()
+	}
+	go func() {
+		for i := range args {
+			jobs <- i
 		}
-		fmt.Printf("%s%s%s\n\n", pre, text, pos)
+		close(jobs)
+	}()
+	for i := range args {
+		emit(<-results[i])
 	}
 }
 
 func main() {
-	ext := model.NewExtractor()
-	for _, input := range util.GetInput(os.Args[1:]) {
-		if document, err := html.NewDocument(input.Data); err == nil {
-			if article, err := ext.Extract(document); err == nil {
-				// Extraction might miss the article heading. So if the text
-				// doesn't start with a heading, use the article title as
-				// opening heading.
-				if !article.StartsWithHeading() && article.Title != "" {
-					article.Prepend(util.Heading(article.Title))
+	flag.Parse()
+	switch *format {
+	case "text", "json", "markdown", "html", "reader":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	switch *extract {
+	case "article", "links":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown extract mode %q\n", *extract)
+		os.Exit(2)
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+
+	articles := make([]*jsonArticle, 0)
+	processInputs(args, util.NewClient(*timeout, *userAgent), *concurrency, func(res result) {
+		switch {
+		case res.Err != nil:
+			fmt.Fprintln(os.Stderr, res.Err)
+		case res.Debug != "":
+			fmt.Printf("# %s\n%s", res.Origin, res.Debug)
+		case res.Links != nil:
+			printLinks(os.Stdout, res.Links)
+		case res.Article == nil:
+			return
+		case *format == "json":
+			articles = append(articles, newJSONArticle(res.Origin, res.Article))
+		default:
+			// Extraction might miss the article heading. So if the text
+			// doesn't start with a heading, use the article title as
+			// opening heading.
+			article := res.Article
+			if !article.StartsWithHeading() && article.Title != "" {
+				article.Prepend(util.Heading{Level: 1, Text: article.Title})
+			}
+			switch *format {
+			case "markdown":
+				fmt.Println(article.Markdown())
+			case "html":
+				fmt.Println(article.HTML())
+			case "reader":
+				if page, err := article.ReaderHTML(); err == nil {
+					fmt.Print(page)
+				} else {
+					fmt.Fprintln(os.Stderr, err)
 				}
-				printArticle(article)
+			default:
+				printArticle(os.Stdout, article)
 			}
 		}
-		input.Data.Close()
+	})
+	if *format == "json" {
+		printJSON(articles)
 	}
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/slyrz/newscat/html"
+	"github.com/slyrz/newscat/model"
+	"github.com/slyrz/newscat/util"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPage = `<html>
+<head>
+	<title>Story %[1]d</title>
+</head>
+<body>
+	<article>
+		<h1>Story %[1]d</h1>
+		<p>A powerful storm swept across the northern coast on Tuesday,
+		knocking out power to thousands of homes and forcing the closure of
+		several major roads. Officials said the damage was extensive.</p>
+		<p>Emergency crews worked through the night to restore electricity.
+		The regional governor declared a state of emergency early on
+		Wednesday morning and asked residents to stay indoors.</p>
+	</article>
+</body>
+</html>`
+
+func TestProcessInputs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		if _, err := fmt.Sscanf(r.URL.Path, "/story/%d", &n); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, testPage, n)
+	}))
+	defer server.Close()
+
+	args := make([]string, 0)
+	for i := 0; i < 10; i++ {
+		args = append(args, fmt.Sprintf("%s/story/%d", server.URL, i))
+	}
+	args = append(args, server.URL+"/missing")
+
+	results := make([]result, 0)
+	processInputs(args, util.NewClient(util.DefaultTimeout, util.DefaultUserAgent), 4, func(res result) {
+		results = append(results, res)
+	})
+	if len(results) != len(args) {
+		t.Fatalf("got %d results, want %d", len(results), len(args))
+	}
+	for i, res := range results[:10] {
+		if res.Origin != args[i] {
+			t.Errorf("result %d has origin %q", i, res.Origin)
+		}
+		if res.Err != nil || res.Article == nil {
+			t.Errorf("result %d: unexpected error %v", i, res.Err)
+			continue
+		}
+		if want := fmt.Sprintf("Story %d", i); res.Article.Title != want {
+			t.Errorf("result %d has title %q, want %q", i, res.Article.Title, want)
+		}
+	}
+	if res := results[10]; res.Err == nil || !strings.Contains(res.Err.Error(), "404") {
+		t.Errorf("expected status error, got %v", res.Err)
+	}
+}
+
+func TestProcessInputErrors(t *testing.T) {
+	// The page exceeds the nesting limit of the HTML parser.
+	page := strings.Repeat("<div>", 20000) + "Text"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(file, []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ext := model.NewExtractor()
+	client := util.NewClient(util.DefaultTimeout, "")
+	for _, arg := range []string{server.URL, file} {
+		res := processInput(arg, client, ext)
+		if !errors.Is(res.Err, html.ErrParse) || !strings.Contains(res.Err.Error(), arg) {
+			t.Errorf("%s: expected parse error, got %v", arg, res.Err)
+		}
+	}
+}
+
+func TestPrintArticle(t *testing.T) {
+	article := &util.Article{}
+	article.Append(util.Heading{Level: 1, Text: "Story"})
+	article.Append(util.Paragraph("First."))
+	article.Append(util.Paragraph("Second."))
+
+	defer func(h bool) { highlight = h }(highlight)
+	highlight = false
+
+	var buf bytes.Buffer
+	printArticle(&buf, article)
+	if got := buf.String(); got != "Story\n\nFirst.\n\nSecond.\n\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestPrintLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><p><a href="/story">Storm hits the coast</a></p>
+			<div><a href="/gallery"><img src="storm.jpg" alt="Storm damage"></a></div></body></html>`)
+	}))
+	defer server.Close()
+
+	defer func(e string, l bool) { *extract, *linkText = e, l }(*extract, *linkText)
+	*extract = "links"
+
+	res := processInput(server.URL+"/news/", util.NewClient(util.DefaultTimeout, ""), model.NewExtractor())
+	if res.Err != nil || res.Article != nil {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	tests := []struct {
+		text bool
+		want string
+	}{
+		{true, server.URL + "/story\tStorm hits the coast\n" + server.URL + "/gallery\tStorm damage\n"},
+		{false, server.URL + "/story\n" + server.URL + "/gallery\n"},
+	}
+	for _, test := range tests {
+		*linkText = test.text
+		var buf bytes.Buffer
+		printLinks(&buf, res.Links)
+		if got := buf.String(); got != test.want {
+			t.Errorf("link text %v: got %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testPage, 1)
+	}))
+	defer server.Close()
+
+	defer func(h bool) { highlight = h }(highlight)
+	highlight = false
+
+	// Print the article like main does by default.
+	var buf bytes.Buffer
+	processInputs([]string{server.URL}, util.NewClient(util.DefaultTimeout, ""), 1, func(res result) {
+		if res.Article == nil {
+			t.Fatalf("nothing extracted: %v", res.Err)
+		}
+		if !res.Article.StartsWithHeading() {
+			res.Article.Prepend(util.Heading{Level: 1, Text: res.Article.Title})
+		}
+		printArticle(&buf, res.Article)
+	})
+
+	doc, err := html.NewDocumentFromString(fmt.Sprintf(testPage, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := model.Content(doc); got != buf.String() {
+		t.Errorf("Content() = %q, want %q", got, buf.String())
+	}
+}
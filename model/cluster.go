@@ -47,6 +47,8 @@ func (cl *cluster) Add(chunk *html.Chunk, args ...float32) {
 }
 
 // Score calculates the weighted average of all chunk scores in cluster.
+// The average is cached until the next call to Add. Clusters with a total
+// weight of zero have a score of zero.
 func (cl *cluster) Score() float32 {
 	if cl.changed {
 		var s float32 = 0.0
@@ -55,12 +57,25 @@ func (cl *cluster) Score() float32 {
 			s += cl.Weights[i] * cl.Scores[i]
 			w += cl.Weights[i]
 		}
-		cl.average = s / w
+		if w != 0.0 {
+			cl.average = s / w
+		} else {
+			cl.average = 0.0
+		}
 		cl.changed = false
 	}
 	return cl.average
 }
 
+// Words returns the total number of words of all chunks in cluster.
+func (cl *cluster) Words() int {
+	result := 0
+	for _, chunk := range cl.Chunks {
+		result += chunk.Text.Words
+	}
+	return result
+}
+
 // newClusterMap creates and initalizes a new clusterMap.
 func newClusterMap() clusterMap {
 	return make(clusterMap)
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/slyrz/newscat/html"
+	"testing"
+)
+
+func TestClusterScore(t *testing.T) {
+	cl := newCluster()
+	cl.Add(new(html.Chunk), 1.0, 1.0)
+	cl.Add(new(html.Chunk), 3.0, 1.0)
+	if cl.Score() != 2.0 {
+		t.Errorf("unexpected score")
+	}
+
+	// Modify the scores behind the cluster's back. A cached average must not
+	// notice.
+	cl.Scores[0] = 5.0
+	if cl.Score() != 2.0 {
+		t.Errorf("score was recomputed")
+	}
+
+	// Adding a chunk invalidates the cache.
+	cl.Add(new(html.Chunk), 5.0, 2.0)
+	if cl.Score() != 4.5 {
+		t.Errorf("score was not recomputed")
+	}
+}
+
+func TestClusterScoreZeroWeight(t *testing.T) {
+	cl := newCluster()
+	cl.Add(new(html.Chunk), 1.0, 0.0)
+	if cl.Score() != 0.0 {
+		t.Errorf("expected zero score")
+	}
+}
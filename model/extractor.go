@@ -1,25 +1,187 @@
 package model
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
 	"github.com/slyrz/newscat/html"
 	"github.com/slyrz/newscat/util"
+	gonet "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
+// defaultBoilerplateWords is the default list of phrases which mark texts
+// at the end of an article as boilerplate if the texts start with them.
+var defaultBoilerplateWords = []string{
+	"advertisement",
+	"follow us",
+	"more on this",
+	"read more",
+	"related",
+	"share (?:on|this|via)",
+	"sign up",
+	"subscribe",
+}
+
+var defaultBoilerplate = newBoilerplateRegex(defaultBoilerplateWords)
+
+// emphasisElements are the elements whose text KeepEmphasis keeps.
+var emphasisElements = map[atom.Atom]bool{
+	atom.B:      true,
+	atom.Em:     true,
+	atom.Strong: true,
+}
+
 var (
 	ErrNoChunks    = errors.New("document contains no chunks")
 	ErrEmptyResult = errors.New("nothing found")
 )
 
+// The default settings of Extractors.
+const (
+	DefaultMaxLinkDensity = 0.5
+	DefaultMinSentences   = 3
+)
+
 // Extractor utilizes the trained model to extract relevant html.Chunks from
 // an html.Document.
 type Extractor struct {
 	Labels []bool
+	// MinWords is the minimum number of words a block of text must contain
+	// to become part of the result. Shorter blocks, like stray captions or
+	// "Advertisement" labels, are dropped. Headings are exempt. The default
+	// of 0 keeps all blocks.
+	MinWords int
+	// AsidePenalty lowers the scores of chunks inside <aside> elements,
+	// which mostly contain sidebars and related content. Their scores are
+	// multiplied by 1 - AsidePenalty, so 1 excludes them entirely. The
+	// default of 0 leaves the scores untouched.
+	AsidePenalty float32
+	// MainBoost raises the scores of chunks inside <main> and <article>
+	// elements, which usually hold the page's primary content. It is added
+	// to their scores, which are capped at 1. The default of 0 leaves the
+	// scores untouched.
+	MainBoost float32
+	// PositionWeight raises the scores of chunks in the middle of the
+	// document, where the main content usually sits, while headers and
+	// footers sit at its ends. Chunks get up to PositionWeight added to their
+	// scores, depending on their distance to the document's ends, capped at
+	// 1. The default of 0 leaves the scores untouched.
+	PositionWeight float32
+	// KeepInlineLinks makes paragraphs which contain links become
+	// util.LinkedParagraphs carrying the links' texts and URLs instead of
+	// plain util.Paragraphs.
+	KeepInlineLinks bool
+	// KeepEmphasis makes paragraphs which contain emphasized text, i.e.
+	// <em>, <strong> or <b> elements, become util.LinkedParagraphs carrying
+	// the emphasized texts.
+	KeepEmphasis bool
+	// KeepStructure makes texts found inside of block quotes and lists
+	// become util.Quotes and util.ListItems instead of plain
+	// util.Paragraphs.
+	KeepStructure bool
+	// Scorer calculates the final score of each chunk. If nil, the
+	// ModelScorer is used.
+	Scorer Scorer
+	// MaxLinkDensity is the maximum link density of blocks of text which
+	// become part of the result. Blocks consisting mostly of link text,
+	// like grids of related stories, are dropped regardless of their score
+	// and size. NewExtractor sets it to DefaultMaxLinkDensity; 0 disables
+	// the cutoff.
+	MaxLinkDensity float32
+	// WordLinkDensity makes the extractor measure the link density of blocks
+	// by the number of words inside and outside of links instead of the
+	// number of letters. Word lengths vary a lot between languages, so word
+	// counts give more stable results on multilingual pages.
+	WordLinkDensity bool
+	// MinSentences is the minimum number of sentences the container holding
+	// most of the relevant text must contain for IsArticle to accept a page.
+	// Link lists consist of many short fragments, but hardly any complete
+	// sentences. NewExtractor sets it to DefaultMinSentences.
+	MinSentences int
+	// Template excludes the boilerplate of the site's template from the
+	// result. Add other documents of the same site to the TemplateDetector
+	// before extracting. If nil, nothing is excluded.
+	Template *TemplateDetector
+	// TrimBoilerplate drops texts at the end of an article which consist
+	// of links only or start with a boilerplate phrase, like "Share on
+	// Facebook" or "Read more".
+	TrimBoilerplate bool
+	// KeepSections keeps the <section> elements of an article together.
+	// If most words of a section are relevant, the remaining blocks of the
+	// section become relevant too, unless they are excluded for other
+	// reasons, like MinWords or MaxLinkDensity. This keeps parts of long
+	// articles which score poorly on their own, e.g. short paragraphs
+	// between section headings.
+	KeepSections bool
+	// MaxTexts is the maximum number of headings, paragraphs, quotes and
+	// list items of the result. Extract stops once the result holds
+	// MaxTexts texts, which is useful for previews. The default of 0 means
+	// no limit.
+	MaxTexts int
+
+	// Unexported fields.
+	boilerplateWords []string       // phrases of boilerplate texts
+	boilerplate      *util.Regex    // regular expression matching boilerplateWords or nil
+	chunkFeatures    []chunkFeature // buffers reused between documents
+	boostFeatures    []boostFeature
+	linkDensity      []float32
 }
 
 // NewExtractor creates and initializes a new Extractor.
 func NewExtractor() *Extractor {
-	return new(Extractor)
+	return &Extractor{
+		MaxLinkDensity:   DefaultMaxLinkDensity,
+		MinSentences:     DefaultMinSentences,
+		boilerplateWords: defaultBoilerplateWords,
+		boilerplate:      defaultBoilerplate,
+	}
+}
+
+// AddBoilerplatePattern adds words to the list of boilerplate phrases used
+// by TrimBoilerplate. Words are matched case-insensitively at the start of
+// texts and may be regular expressions. This is useful for sites in other
+// languages, e.g. AddBoilerplatePattern("mehr zum thema").
+func (ext *Extractor) AddBoilerplatePattern(words ...string) {
+	list := make([]string, 0, len(ext.boilerplateWords)+len(words))
+	list = append(list, ext.boilerplateWords...)
+	list = append(list, words...)
+	ext.SetBoilerplatePatterns(list...)
+}
+
+// SetBoilerplatePatterns replaces the list of boilerplate phrases by words,
+// including the default phrases. Calling it without arguments makes
+// TrimBoilerplate drop link-only texts only.
+func (ext *Extractor) SetBoilerplatePatterns(words ...string) {
+	ext.boilerplateWords = words
+	ext.boilerplate = newBoilerplateRegex(words)
+}
+
+// newBoilerplateRegex returns a regular expression matching texts which
+// start with one of the words or nil if there are no words.
+func newBoilerplateRegex(words []string) *util.Regex {
+	if len(words) == 0 {
+		return nil
+	}
+	return util.NewRegex(`(?i)^\W*(?:` + strings.Join(words, "|") + `)(?:\W|$)`)
+}
+
+// isBoilerplate returns true if the text of cluster consists of links only
+// or starts with a boilerplate phrase.
+func (ext *Extractor) isBoilerplate(cluster *cluster, text string) bool {
+	if ext.boilerplate != nil && ext.boilerplate.In(text) {
+		return true
+	}
+	for _, chunk := range cluster.Chunks {
+		if chunk.Text.Words > 0 && !inLink(chunk) {
+			return false
+		}
+	}
+	return true
 }
 
 // Extract returns a list of relevant text chunks found in doc.
@@ -40,19 +202,324 @@ func NewExtractor() *Extractor {
 // By now you might have noticed that I'm exceptionally bad at naming and
 // describing things properly.
 func (ext *Extractor) Extract(doc *html.Document) (*util.Article, error) {
-	*ext = Extractor{}
+	ext.Labels = nil
+	clusterBlock, err := ext.scoreBlocks(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Label all chunks whose blocks have a score above prediction level.
+	// This makes sure that we don't split large blocks.
+	ext.Labels = make([]bool, len(doc.Chunks))
+	for i, chunk := range doc.Chunks {
+		if cluster, ok := clusterBlock[chunk.Block]; ok {
+			ext.Labels[i] = cluster.Score() > 0.5 && (chunk.IsHeading() || cluster.Words() >= ext.MinWords) &&
+				(ext.MaxLinkDensity <= 0 || ext.linkDensity[i] <= ext.MaxLinkDensity) &&
+				(ext.Template == nil || !ext.Template.IsBoilerplate(chunk))
+		}
+	}
+
+	result := &util.Article{Title: doc.Title.String()}
+	trailing := 0 // number of boilerplate texts at the end of result
+	for i, chunk := range doc.Chunks {
+		if cluster, ok := clusterBlock[chunk.Block]; ok && ext.Labels[i] {
+			text := JoinChunks(cluster.Chunks)
+			links := make([]util.Link, 0)
+			var strong []string
+			for _, chunk := range cluster.Chunks {
+				if ext.KeepInlineLinks && chunk.URL != "" {
+					links = append(links, util.Link{Text: chunk.Text.String(), URL: chunk.URL})
+				}
+				if ext.KeepEmphasis && emphasisElements[chunk.Base.DataAtom] {
+					strong = append(strong, chunk.Text.String())
+				}
+			}
+			switch {
+			case chunk.IsHeading():
+				result.Append(util.Heading{Level: chunk.HeadingLevel, Text: text})
+			case chunk.Preformatted:
+				result.Append(util.Preformatted(text))
+			case ext.KeepStructure && chunk.Ancestors&html.AncestorBlockquote != 0:
+				result.Append(util.Quote(text))
+			case ext.KeepStructure && chunk.Ancestors&html.AncestorList != 0:
+				result.Append(util.ListItem(text))
+			case len(links) > 0 || len(strong) > 0:
+				result.Append(util.LinkedParagraph{Text: text, Links: links, Strong: strong})
+			default:
+				result.Append(util.Paragraph(text))
+			}
+			if ext.TrimBoilerplate && ext.isBoilerplate(cluster, text) {
+				trailing++
+			} else {
+				trailing = 0
+			}
+			delete(clusterBlock, chunk.Block)
+			if ext.MaxTexts > 0 && len(result.Text) >= ext.MaxTexts {
+				break
+			}
+		}
+	}
+	result.Text = result.Text[:len(result.Text)-trailing]
+	if len(result.Text) == 0 {
+		return nil, ErrEmptyResult
+	}
+
+	// Add the metadata readers like to see along with the text.
+	result.Author = doc.Author()
+	result.Image = doc.TopImage("")
+	if date, err := doc.PublishedTime(); err == nil {
+		result.Published = date
+	}
+	return result, nil
+}
+
+// Content returns the plain text of the article found in doc, which is
+// what the newscat command prints by default, using an Extractor with
+// default settings. The text starts with the document's title, unless
+// the article starts with a heading. Content returns an empty string if
+// nothing was found. Use an Extractor to extract many documents.
+func Content(doc *html.Document) string {
+	article, err := NewExtractor().Extract(doc)
+	if err != nil {
+		return ""
+	}
+	if !article.StartsWithHeading() && article.Title != "" {
+		article.Prepend(util.Heading{Level: 1, Text: article.Title})
+	}
+	var buf strings.Builder
+	article.WriteTo(&buf)
+	return buf.String()
+}
+
+// Summary returns the first maxSentences sentences of the article found in
+// doc using an Extractor with default settings, see util.Article.Summary.
+// It returns an empty string if nothing was found.
+func Summary(doc *html.Document, maxSentences int) string {
+	article, err := NewExtractor().Extract(doc)
+	if err != nil {
+		return ""
+	}
+	return article.Summary(maxSentences)
+}
+
+// ExtractParagraphs works like Extract, but returns the article's texts as
+// strings. Each heading, paragraph, quote and list item becomes one string,
+// which joins the chunks of its block. It returns nil if nothing was found.
+func (ext *Extractor) ExtractParagraphs(doc *html.Document) []string {
+	article, err := ext.Extract(doc)
+	if err != nil {
+		return nil
+	}
+	return article.Paragraphs()
+}
+
+// The thresholds used by IsArticle. An article's relevant text must mostly
+// be found in a single container, which must contain a minimum number of
+// words.
+const (
+	articleMinWords = 50
+	articleMinShare = 0.5
+)
+
+// IsArticle returns true if doc looks like an article page. Index pages
+// and category listings consist of many short teasers spread across many
+// containers. Even if the teasers score well, none of their containers
+// holds enough of the relevant text. Link lists kept in a single container
+// lack the sentences of an article, see MinSentences.
+func (ext *Extractor) IsArticle(doc *html.Document) bool {
+	clusterBlock, err := ext.scoreBlocks(doc)
+	if err != nil {
+		return false
+	}
+	top, max, total := topContainer(doc, clusterBlock)
+	if max < articleMinWords || float32(max) < articleMinShare*float32(total) {
+		return false
+	}
+	sentences := 0
+	for _, chunk := range doc.Chunks {
+		if chunk.Container == top && clusterBlock[chunk.Block].Score() > 0.5 {
+			sentences += chunk.Text.Sentences
+		}
+	}
+	return sentences >= ext.MinSentences
+}
+
+// ContentNode returns the element the extractor believes contains the
+// article, which is the container holding most words of the relevant
+// chunks. It returns nil if doc has no relevant chunks. This allows to
+// post-process the article's subtree, e.g. to render it.
+func (ext *Extractor) ContentNode(doc *html.Document) *gonet.Node {
+	clusterBlock, err := ext.scoreBlocks(doc)
+	if err != nil {
+		return nil
+	}
+	node, _, _ := topContainer(doc, clusterBlock)
+	return node
+}
+
+// ContentRatio returns the share of the document's letters found in the
+// relevant chunks of the container holding the article, see ContentNode.
+// Low ratios indicate pages consisting mostly of navigation and other
+// boilerplate or a failed extraction. It returns 0 if doc has no relevant
+// chunks.
+func (ext *Extractor) ContentRatio(doc *html.Document) float32 {
+	clusterBlock, err := ext.scoreBlocks(doc)
+	if err != nil {
+		return 0
+	}
+	top, _, _ := topContainer(doc, clusterBlock)
+	total := doc.Letters()
+	if top == nil || total == 0 {
+		return 0
+	}
+	letters := 0
+	for _, chunk := range doc.Chunks {
+		if chunk.Container == top && clusterBlock[chunk.Block].Score() > 0.5 {
+			letters += chunk.Text.LetterCount()
+		}
+	}
+	return float32(letters) / float32(total)
+}
+
+// topContainer groups the words of relevant chunks by their containers. It
+// returns the container with the most words, its number of words and the
+// total number of relevant words. Of containers having the same number of
+// words, the first one in document order wins.
+func topContainer(doc *html.Document, clusterBlock clusterMap) (*gonet.Node, int, int) {
+	words := make(map[*gonet.Node]int)
+	order := make([]*gonet.Node, 0)
+	total := 0
+	for _, chunk := range doc.Chunks {
+		if clusterBlock[chunk.Block].Score() > 0.5 {
+			if _, ok := words[chunk.Container]; !ok {
+				order = append(order, chunk.Container)
+			}
+			words[chunk.Container] += chunk.Text.Words
+			total += chunk.Text.Words
+		}
+	}
+	var top *gonet.Node
+	max := 0
+	for _, node := range order {
+		if n := words[node]; n > max {
+			top, max = node, n
+		}
+	}
+	return top, max, total
+}
+
+// A ScoredChunk is a chunk and the final score the extractor assigned to it.
+type ScoredChunk struct {
+	Chunk *html.Chunk
+	Score float32
+}
+
+// ExtractScored returns the chunks of doc in document order along with the
+// scores of their blocks. Extract considers chunks with a score above 0.5
+// relevant. This is useful to debug and tune the extraction.
+func (ext *Extractor) ExtractScored(doc *html.Document) ([]ScoredChunk, error) {
+	clusterBlock, err := ext.scoreBlocks(doc)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ScoredChunk, len(doc.Chunks))
+	for i, chunk := range doc.Chunks {
+		result[i] = ScoredChunk{chunk, clusterBlock[chunk.Block].Score()}
+	}
+	return result, nil
+}
+
+// WriteDebug writes the clusters of doc to w, one tab-separated line per
+// cluster sorted by score in descending order. Each line contains the
+// cluster's score, its number of chunks, words and sentences, the link density
+// and the cluster's block node. The first line contains the column names.
+// This exposes the extractor's decisions for tuning.
+func (ext *Extractor) WriteDebug(w io.Writer, doc *html.Document) error {
+	clusterBlock, err := ext.scoreBlocks(doc)
+	if err != nil {
+		return err
+	}
+	type row struct {
+		node    *gonet.Node
+		cluster *cluster
+		density float32
+	}
+	rows := make([]row, 0, len(clusterBlock))
+	for i, chunk := range doc.Chunks {
+		if cluster, ok := clusterBlock[chunk.Block]; ok && cluster.Chunks[0] == chunk {
+			rows = append(rows, row{chunk.Block, cluster, ext.linkDensity[i]})
+		}
+	}
+	// Stable sorting keeps clusters with equal scores in document order.
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].cluster.Score() > rows[j].cluster.Score()
+	})
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "score\tchunks\twords\tsentences\tlinkdensity\tnode")
+	for _, r := range rows {
+		sentences := 0
+		for _, chunk := range r.cluster.Chunks {
+			sentences += chunk.Text.Sentences
+		}
+		fmt.Fprintf(bw, "%.3f\t%d\t%d\t%d\t%.3f\t%s\n", r.cluster.Score(), len(r.cluster.Chunks),
+			r.cluster.Words(), sentences, r.density, describeNode(r.node))
+	}
+	return bw.Flush()
+}
+
+// describeNode returns a CSS selector like description of n, e.g.
+// "div#main.content".
+func describeNode(n *gonet.Node) string {
+	result := n.Data
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "id":
+			result += "#" + attr.Val
+		case "class":
+			for _, class := range strings.Fields(attr.Val) {
+				result += "." + class
+			}
+		}
+	}
+	return result
+}
+
+// scoreBlocks scores the chunks of doc and returns them clustered by their
+// block nodes.
+func (ext *Extractor) scoreBlocks(doc *html.Document) (clusterMap, error) {
 	if len(doc.Chunks) == 0 {
 		return nil, ErrNoChunks
 	}
 
-	chunkFeatures := make([]chunkFeature, len(doc.Chunks))
-	boostFeatures := make([]boostFeature, len(doc.Chunks))
+	// Reuse the feature buffers of previous documents if they are large
+	// enough. The feature writers don't write every element, so clear them.
+	if cap(ext.chunkFeatures) < len(doc.Chunks) {
+		ext.chunkFeatures = make([]chunkFeature, len(doc.Chunks))
+		ext.boostFeatures = make([]boostFeature, len(doc.Chunks))
+	}
+	chunkFeatures := ext.chunkFeatures[:len(doc.Chunks)]
+	boostFeatures := ext.boostFeatures[:len(doc.Chunks)]
+	for i := range chunkFeatures {
+		chunkFeatures[i] = chunkFeature{}
+		boostFeatures[i] = boostFeature{}
+	}
 
 	// Count the number of words and sentences we encountered for each
 	// class. This helps us to detect elements that contain the doc text.
 	classStats := doc.GetClassStats()
 	clusterStats := doc.GetClusterStats()
 
+	if cap(ext.linkDensity) < len(doc.Chunks) {
+		ext.linkDensity = make([]float32, len(doc.Chunks))
+	}
+	linkDensity := ext.linkDensity[:len(doc.Chunks)]
+	for i, chunk := range doc.Chunks {
+		linkDensity[i] = chunk.LinkText
+	}
+	if ext.WordLinkDensity {
+		wordLinkDensity(doc, linkDensity)
+	}
+
 	chunkFeatureWriter := new(chunkFeatureWriter)
 	for i, chunk := range doc.Chunks {
 		chunkFeatureWriter.Assign(chunkFeatures[i][:])
@@ -60,7 +527,7 @@ func (ext *Extractor) Extract(doc *html.Document) (*util.Article, error) {
 		chunkFeatureWriter.WriteParentType(chunk)
 		chunkFeatureWriter.WriteSiblingTypes(chunk)
 		chunkFeatureWriter.WriteAncestors(chunk)
-		chunkFeatureWriter.WriteTextStat(chunk)
+		chunkFeatureWriter.WriteTextStat(chunk, linkDensity[i])
 		chunkFeatureWriter.WriteTextStatSiblings(chunk)
 		chunkFeatureWriter.WriteClassStat(chunk, classStats)
 		chunkFeatureWriter.WriteClusterStat(chunk, clusterStats)
@@ -103,43 +570,201 @@ func (ext *Extractor) Extract(doc *html.Document) (*util.Article, error) {
 	boostFeatureWriter := new(boostFeatureWriter)
 	for i, chunk := range doc.Chunks {
 		boostFeatureWriter.Assign(boostFeatures[i][:])
-		boostFeatureWriter.WriteChunk(chunk)
+		boostFeatureWriter.WriteChunk(chunk, linkDensity[i])
 		boostFeatureWriter.WriteCluster(chunk, clusterContainer[chunk.Container])
 		boostFeatureWriter.WriteTitleSimilarity(chunk, doc.Title)
 	}
 
+	var scorer Scorer = ModelScorer{}
+	if ext.Scorer != nil {
+		scorer = ext.Scorer
+	}
+
 	// Cluster chunks by block.
 	clusterBlock := newClusterMap()
 	for i, chunk := range doc.Chunks {
-		clusterBlock.Add(chunk.Block, chunk, boostFeatures[i].Score(), float32(chunk.Text.Len()))
+		ctx := ScoreContext{
+			Doc:         doc,
+			Prev:        chunk.Prev,
+			Next:        chunk.Next,
+			LinkDensity: linkDensity[i],
+			Ancestors:   chunk.Ancestors,
+			ClassStats:  classStats,
+			ModelScore:  boostFeatures[i].Score(),
+		}
+		score := scorer.Score(chunk, ctx)
+		if chunk.Ancestors&html.AncestorAside != 0 {
+			score *= 1.0 - ext.AsidePenalty
+		}
+		if chunk.Ancestors&(html.AncestorMain|html.AncestorArticle) != 0 {
+			if score += ext.MainBoost; score > 1.0 {
+				score = 1.0
+			}
+		}
+		if ext.PositionWeight != 0 && len(doc.Chunks) > 1 {
+			// The prior is 1 in the middle of the chunk sequence and falls
+			// linearly to 0 at both ends.
+			dist := 2.0*float32(i)/float32(len(doc.Chunks)-1) - 1.0
+			if dist < 0 {
+				dist = -dist
+			}
+			if score += ext.PositionWeight * (1.0 - dist); score > 1.0 {
+				score = 1.0
+			}
+		}
+		clusterBlock.Add(chunk.Block, chunk, score, float32(chunk.Text.Len()))
+	}
+	if ext.KeepSections {
+		keepSections(doc, clusterBlock)
 	}
+	return clusterBlock, nil
+}
 
-	// Label all chunks whose blocks have a score above prediction level.
-	// This makes sure that we don't split large blocks.
-	ext.Labels = make([]bool, len(doc.Chunks))
-	for i, chunk := range doc.Chunks {
-		if cluster, ok := clusterBlock[chunk.Block]; ok {
-			ext.Labels[i] = cluster.Score() > 0.5
+// keepSections raises the scores of the clusters in sections whose words
+// are mostly relevant to the share of relevant words, which makes them
+// relevant too.
+func keepSections(doc *html.Document, clusterBlock clusterMap) {
+	type count struct {
+		relevant int
+		total    int
+	}
+	counts := make(map[*gonet.Node]*count)
+	for _, chunk := range doc.Chunks {
+		section := innermostSection(chunk)
+		if section == nil {
+			continue
+		}
+		c, ok := counts[section]
+		if !ok {
+			c = new(count)
+			counts[section] = c
 		}
+		if clusterBlock[chunk.Block].Score() > 0.5 {
+			c.relevant += chunk.Text.Words
+		}
+		c.total += chunk.Text.Words
 	}
+	for _, cluster := range clusterBlock {
+		c := counts[innermostSection(cluster.Chunks[0])]
+		if c == nil || 2*c.relevant <= c.total {
+			continue
+		}
+		share := float32(c.relevant) / float32(c.total)
+		for i, score := range cluster.Scores {
+			if score < share {
+				cluster.Scores[i] = share
+			}
+		}
+		cluster.changed = true
+	}
+}
 
-	result := &util.Article{Title: doc.Title.String()}
+// innermostSection returns the innermost <section> element enclosing the
+// chunk's block or nil if there is none.
+func innermostSection(chunk *html.Chunk) *gonet.Node {
+	if chunk.Ancestors&html.AncestorSection == 0 {
+		return nil
+	}
+	for n := chunk.Block; n != nil; n = n.Parent {
+		if n.DataAtom == atom.Section {
+			return n
+		}
+	}
+	return nil
+}
+
+// inLink returns true if the text of chunk is the text of a link, i.e. its
+// base element or one of the elements between base and block is an <a>
+// element. This covers link texts wrapped in inline markup like <b>.
+func inLink(chunk *html.Chunk) bool {
+	for n := chunk.Base; n != nil; n = n.Parent {
+		if n.DataAtom == atom.A {
+			return true
+		}
+		if n == chunk.Block {
+			break
+		}
+	}
+	return false
+}
+
+// wordLinkDensity stores the ratio of words inside links to all words of
+// each chunk's block in result.
+func wordLinkDensity(doc *html.Document, result []float32) {
+	type wordCount struct{ link, norm int }
+	counts := make(map[*gonet.Node]*wordCount)
+	for _, chunk := range doc.Chunks {
+		count, ok := counts[chunk.Block]
+		if !ok {
+			count = new(wordCount)
+			counts[chunk.Block] = count
+		}
+		if inLink(chunk) {
+			count.link += chunk.Text.Words
+		} else {
+			count.norm += chunk.Text.Words
+		}
+	}
 	for i, chunk := range doc.Chunks {
-		if cluster, ok := clusterBlock[chunk.Block]; ok && ext.Labels[i] {
-			text := util.NewText()
-			for _, chunk := range cluster.Chunks {
-				text.WriteText(chunk.Text)
-			}
-			if chunk.IsHeading() {
-				result.Append(util.Heading(text.String()))
-			} else {
-				result.Append(util.Paragraph(text.String()))
-			}
-			delete(clusterBlock, chunk.Block)
+		result[i] = 0.0
+		if count := counts[chunk.Block]; count.link > 0 {
+			result[i] = float32(count.link) / float32(count.link+count.norm)
 		}
 	}
-	if len(result.Text) == 0 {
-		return nil, ErrEmptyResult
+}
+
+// ExtractLimited works like Extract, but processes only the first maxChunks
+// chunks of doc. This caps the work spent on very large documents. Since the
+// remaining chunks are ignored entirely, the statistics used for scoring
+// only cover the processed chunks and the result is a deterministic function
+// of them. The scores depend on statistics of all processed chunks, so they
+// can't be computed incrementally. To stop after a number of texts, set
+// MaxTexts.
+func (ext *Extractor) ExtractLimited(doc *html.Document, maxChunks int) (*util.Article, error) {
+	if maxChunks < 0 {
+		maxChunks = 0
 	}
-	return result, nil
+	if maxChunks < len(doc.Chunks) {
+		limited := *doc
+		limited.Chunks = doc.Chunks[:maxChunks]
+		// The last processed chunk must not lead to the ignored ones. The
+		// chunks belong to doc, so change a copy.
+		if maxChunks > 0 {
+			last := *doc.Chunks[maxChunks-1]
+			last.Next = nil
+			limited.Chunks = append(limited.Chunks[:maxChunks-1:maxChunks-1], &last)
+		}
+		doc = &limited
+	}
+	return ext.Extract(doc)
+}
+
+// Punctuation JoinChunks puts no spaces in front of or behind.
+const (
+	closingPunctuation = ",.;:!?)]}%»”’…"
+	openingPunctuation = "([{«“‘"
+)
+
+// JoinChunks joins the texts of chunks, which usually belong to the same
+// block, with spaces. Other than util.Text, it puts no spaces in front of
+// closing punctuation like commas and periods or behind opening brackets.
+// Otherwise, inline elements like <em> or <a> ending right before a comma
+// would leave "word ,punctuation" artifacts.
+func JoinChunks(chunks []*html.Chunk) string {
+	var b strings.Builder
+	prev := ""
+	for _, chunk := range chunks {
+		s := chunk.Text.String()
+		if s == "" {
+			continue
+		}
+		first, _ := utf8.DecodeRuneInString(s)
+		last, _ := utf8.DecodeLastRuneInString(prev)
+		if b.Len() > 0 && !strings.ContainsRune(closingPunctuation, first) && !strings.ContainsRune(openingPunctuation, last) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s)
+		prev = s
+	}
+	return b.String()
 }
@@ -0,0 +1,932 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/slyrz/newscat/html"
+	"github.com/slyrz/newscat/util"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPage = `<html>
+<head>
+	<title>Storm hits the coast</title>
+</head>
+<body>
+	<div class="menu">
+		<ul>
+			<li><a href="/world">World</a></li>
+			<li><a href="/politics">Politics</a></li>
+			<li><a href="/sports">Sports</a></li>
+		</ul>
+	</div>
+	<article>
+		<h1>Storm hits the coast</h1>
+		<p>A powerful storm swept across the northern coast on Tuesday,
+		knocking out power to thousands of homes and forcing the closure of
+		several major roads. Officials said the damage was extensive.</p>
+		<p>Emergency crews worked through the night to restore electricity.
+		The regional governor declared a state of emergency early on
+		Wednesday morning and asked residents to stay indoors.</p>
+		<p>Meteorologists expect the weather to calm down by the end of the
+		week. However, they warned that further flooding remains possible in
+		low-lying areas along the river.</p>
+	</article>
+	<aside>
+		<ul>
+			<li><a href="/other-story">Read another story here</a></li>
+			<li><a href="/more-stories">Read more stories there</a></li>
+		</ul>
+	</aside>
+</body>
+</html>`
+
+func extractTestPage(t *testing.T, ext *Extractor, page string) (*html.Document, *util.Article) {
+	doc, err := html.NewDocument(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	article, err := ext.Extract(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc, article
+}
+
+// contains returns true if one of the article's texts contains text.
+func contains(article *util.Article, text string) bool {
+	for _, v := range article.Text {
+		if strings.Contains(fmt.Sprint(v), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExtract(t *testing.T) {
+	_, article := extractTestPage(t, NewExtractor(), testPage)
+	if article.Title != "Storm hits the coast" {
+		t.Errorf("unexpected title %q", article.Title)
+	}
+	if !article.StartsWithHeading() {
+		t.Errorf("article doesn't start with heading")
+	}
+	if len(article.Text) != 4 {
+		t.Fatalf("unexpected number of texts: %d", len(article.Text))
+	}
+	if heading := article.Text[0].(util.Heading); heading.Level != 1 {
+		t.Errorf("unexpected heading level %d", heading.Level)
+	}
+	for _, text := range article.Text[1:] {
+		if _, ok := text.(util.Paragraph); !ok {
+			t.Errorf("expected paragraph, got %T", text)
+		}
+	}
+}
+
+func TestExtractLimited(t *testing.T) {
+	doc, article := extractTestPage(t, NewExtractor(), testPage)
+
+	// The first five chunks are three menu links, the heading and the first
+	// paragraph.
+	ext := NewExtractor()
+	limited, err := ext.ExtractLimited(doc, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ext.Labels) != 5 {
+		t.Errorf("processed %d chunks", len(ext.Labels))
+	}
+	if len(limited.Text) != 2 || limited.Text[0] != article.Text[0] || limited.Text[1] != article.Text[1] {
+		t.Errorf("unexpected result %v", limited.Text)
+	}
+
+	again, _ := ext.ExtractLimited(doc, 5)
+	if len(again.Text) != len(limited.Text) {
+		t.Errorf("result is not deterministic")
+	}
+
+	if _, err := ext.ExtractLimited(doc, 0); err != ErrNoChunks {
+		t.Errorf("expected ErrNoChunks")
+	}
+
+	// Scorers must not see the ignored chunks.
+	scorer := nextScorer{next: make(map[*html.Chunk]*html.Chunk)}
+	ext.Scorer = scorer
+	if _, err := ext.ExtractLimited(doc, 5); err != nil {
+		t.Fatal(err)
+	}
+	for chunk, next := range scorer.next {
+		if chunk.Text.String() == doc.Chunks[4].Text.String() && next != nil {
+			t.Errorf("last chunk leads to ignored chunk %q", next.Text.String())
+		}
+	}
+	if doc.Chunks[4].Next != doc.Chunks[5] {
+		t.Errorf("document was modified")
+	}
+}
+
+// nextScorer records the chunks following the scored chunks.
+type nextScorer struct {
+	ModelScorer
+	next map[*html.Chunk]*html.Chunk
+}
+
+func (s nextScorer) Score(chunk *html.Chunk, ctx ScoreContext) float32 {
+	s.next[chunk] = ctx.Next
+	return s.ModelScorer.Score(chunk, ctx)
+}
+
+func TestExtractMaxTexts(t *testing.T) {
+	_, article := extractTestPage(t, NewExtractor(), testPage)
+	for _, max := range []int{1, 2, 100} {
+		ext := NewExtractor()
+		ext.MaxTexts = max
+		_, limited := extractTestPage(t, ext, testPage)
+		want := article.Text
+		if max < len(want) {
+			want = want[:max]
+		}
+		if fmt.Sprint(limited.Text) != fmt.Sprint(want) {
+			t.Errorf("MaxTexts %d: got %v, want %v", max, limited.Text, want)
+		}
+	}
+}
+
+func TestExtractMinWords(t *testing.T) {
+	page := strings.Replace(testPage, "<p>Emergency", "<p>Photo credits</p>\n\t\t<p>Emergency", 1)
+
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if len(article.Text) != 5 || article.Text[2] != util.Paragraph("Photo credits") {
+		t.Fatalf("expected fragment in result %v", article.Text)
+	}
+
+	ext := NewExtractor()
+	ext.MinWords = 4
+	_, article = extractTestPage(t, ext, page)
+	if len(article.Text) != 4 {
+		t.Fatalf("unexpected number of texts: %d", len(article.Text))
+	}
+	if !article.StartsWithHeading() {
+		t.Errorf("heading was dropped")
+	}
+	for _, text := range article.Text {
+		if text == util.Paragraph("Photo credits") {
+			t.Errorf("fragment wasn't dropped")
+		}
+	}
+}
+
+func TestExtractScored(t *testing.T) {
+	doc, err := html.NewDocument(strings.NewReader(testPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scored, err := NewExtractor().ExtractScored(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scored) != len(doc.Chunks) {
+		t.Fatalf("got %d scores for %d chunks", len(scored), len(doc.Chunks))
+	}
+
+	// The dense article paragraphs must outscore every link of the menu and
+	// the aside.
+	var minText, maxLink float32 = 1.0, 0.0
+	for i, sc := range scored {
+		if sc.Chunk != doc.Chunks[i] {
+			t.Errorf("chunk %d out of order", i)
+		}
+		switch {
+		case sc.Chunk.LinkText > 0 && sc.Score > maxLink:
+			maxLink = sc.Score
+		case sc.Chunk.LinkText == 0 && !sc.Chunk.IsHeading() && sc.Score < minText:
+			minText = sc.Score
+		}
+	}
+	if minText <= 0.5 || maxLink >= 0.5 || minText <= maxLink {
+		t.Errorf("unexpected scores: text %f, links %f", minText, maxLink)
+	}
+}
+
+func TestExtractAsidePenalty(t *testing.T) {
+	page := strings.Replace(testPage, "<aside>", `<aside>
+		<p>Our newsletter keeps you informed about storms, floods and other
+		weather events in the region. Sign up today and receive a summary of
+		the most important news every morning, written by our editors.</p>`, 1)
+
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if !contains(article, "newsletter") {
+		t.Fatalf("aside wasn't extracted without penalty")
+	}
+
+	ext := NewExtractor()
+	ext.AsidePenalty = 0.5
+	_, article = extractTestPage(t, ext, page)
+	if contains(article, "newsletter") {
+		t.Errorf("aside was extracted despite penalty")
+	}
+	if !contains(article, "Emergency crews") {
+		t.Errorf("article text is missing")
+	}
+}
+
+func TestExtractMainBoost(t *testing.T) {
+	page := `<html><head><title>Storm hits the coast</title></head><body>
+	<div class="sidebar">
+		<p>Subscribe to our newsletter to get the latest updates about the weather,
+		traffic and local events delivered to your inbox every single morning. It is
+		free and you can cancel at any time. More than ten thousand readers already
+		rely on it to plan their day.</p>
+		<p>Our journalists work hard to bring you accurate reporting. Please consider
+		supporting independent journalism with a monthly donation, which helps us to
+		keep our reporting free for everyone in the region.</p>
+	</div>
+	<main>
+		<p>Storm: <a href="/a">power</a> out, <a href="/b">roads</a> closed.</p>
+		<p>Crews <a href="/c">worked</a> all night.</p>
+		<p>More <a href="/d">flooding</a> is possible.</p>
+	</main>
+	</body></html>`
+
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if contains(article, "flooding") {
+		t.Fatalf("main content was extracted without boost")
+	}
+
+	ext := NewExtractor()
+	ext.MainBoost = 0.3
+	_, article = extractTestPage(t, ext, page)
+	for _, text := range []string{"power", "Crews", "flooding"} {
+		if !contains(article, text) {
+			t.Errorf("main content %q is missing", text)
+		}
+	}
+}
+
+func TestExtractTrimBoilerplate(t *testing.T) {
+	page := strings.Replace(testPage, "</article>", `
+		<p>Related officials said they would review the emergency plans for
+		the coast once the weather calms down.</p>
+		<p>Emergency shelters remain open for everyone who can't return home.</p>
+		<p>Share on Facebook, Twitter or via email with your friends and family.</p>
+		<p>Mehr zum Thema: Wetter und Klima an der Nordsee in diesem Jahr.</p>
+	</article>`, 1)
+
+	texts := func(article *util.Article) string {
+		result := make([]string, len(article.Text))
+		for i, v := range article.Text {
+			result[i] = fmt.Sprint(v)
+		}
+		return strings.Join(result, "\n")
+	}
+
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if s := texts(article); !strings.Contains(s, "Share on Facebook") || !strings.Contains(s, "Mehr zum Thema") {
+		t.Fatalf("boilerplate wasn't extracted without trimming:\n%s", s)
+	}
+
+	ext := NewExtractor()
+	ext.TrimBoilerplate = true
+	ext.AddBoilerplatePattern("mehr zum thema")
+	_, article = extractTestPage(t, ext, page)
+	s := texts(article)
+	for _, text := range []string{"Share on Facebook", "Mehr zum Thema"} {
+		if strings.Contains(s, text) {
+			t.Errorf("boilerplate %q wasn't trimmed:\n%s", text, s)
+		}
+	}
+	for _, text := range []string{"Related officials", "Emergency shelters"} {
+		if !strings.Contains(s, text) {
+			t.Errorf("text %q is missing:\n%s", text, s)
+		}
+	}
+
+	// The model rejects most links by itself, so favor the links in the
+	// aside to check that trailing link-only texts are trimmed.
+	page = strings.Replace(testPage, "<aside>", `<aside class="recommended">`, 1)
+	ext.Scorer = recommendedScorer{}
+	ext.MaxLinkDensity = 0
+	_, article = extractTestPage(t, ext, page)
+	if s := texts(article); strings.Contains(s, "Read another story") || !strings.Contains(s, "further flooding") {
+		t.Errorf("links weren't trimmed:\n%s", s)
+	}
+}
+
+func TestJoinChunks(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`<p>See <em>this</em>, and <a href="/x">that</a>. Done</p>`, "See this, and that. Done"},
+		{`<p>Then (<b>bracket</b>) and “<i>quote</i>”.</p>`, "Then (bracket) and “quote”."},
+		{`<p><strong>Really</strong>? <em>Yes</em>!</p>`, "Really? Yes!"},
+		{`<p>Plain <em>emphasis</em> inside</p>`, "Plain emphasis inside"},
+	}
+	for _, test := range tests {
+		doc, err := html.NewDocument(strings.NewReader("<html><body>" + test.body + "</body></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := JoinChunks(doc.Chunks); got != test.want {
+			t.Errorf("JoinChunks() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestExtractKeepInlineLinks(t *testing.T) {
+	page := strings.Replace(testPage, "several major roads. Officials said",
+		`several <a href="/roads">major roads</a>. <a href="http://example.com/officials">Officials</a> said`, 1)
+	doc, err := html.NewDocument(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ext := NewExtractor()
+	article, err := ext.Extract(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := article.Text[1].(util.Paragraph); !ok {
+		t.Errorf("expected paragraph, got %T", article.Text[1])
+	}
+
+	ext.KeepInlineLinks = true
+	article, err = ext.Extract(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(article.Text) != 4 {
+		t.Fatalf("unexpected number of texts: %d", len(article.Text))
+	}
+	para, ok := article.Text[1].(util.LinkedParagraph)
+	if !ok {
+		t.Fatalf("expected linked paragraph, got %T", article.Text[1])
+	}
+	if !strings.Contains(para.Text, "several major roads") || !strings.Contains(para.Text, "Officials said") {
+		t.Errorf("links weren't merged into the text %q", para.Text)
+	}
+	want := []util.Link{
+		{Text: "major roads", URL: "/roads"},
+		{Text: "Officials", URL: "http://example.com/officials"},
+	}
+	if len(para.Links) != len(want) || para.Links[0] != want[0] || para.Links[1] != want[1] {
+		t.Errorf("unexpected links %v", para.Links)
+	}
+}
+
+func TestExtractKeepStructure(t *testing.T) {
+	page := strings.Replace(testPage, "<p>Emergency crews", "<blockquote><p>Emergency crews", 1)
+	page = strings.Replace(page, "stay indoors.</p>", "stay indoors.</p></blockquote>", 1)
+
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if _, ok := article.Text[2].(util.Paragraph); !ok {
+		t.Errorf("expected paragraph, got %T", article.Text[2])
+	}
+
+	ext := NewExtractor()
+	ext.KeepStructure = true
+	_, article = extractTestPage(t, ext, page)
+	if quote, ok := article.Text[2].(util.Quote); !ok || !strings.HasPrefix(string(quote), "Emergency crews") {
+		t.Errorf("expected quote, got %T %q", article.Text[2], article.Text[2])
+	}
+}
+
+func TestExtractPreformatted(t *testing.T) {
+	const code = "for i := 0; i < 3; i++ {\n\tfmt.Println(\"storm  warning\")\n}"
+	page := strings.Replace(testPage, "</article>", "<pre>"+code+"</pre></article>", 1)
+	page = strings.Replace(page, "<p>Emergency crews", "<p>The following program prints the warning:</p><p>Emergency crews", 1)
+
+	parser := html.NewParser()
+	parser.KeepPreformatted = true
+	doc, err := parser.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	article, err := NewExtractor().Extract(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, text := range article.Text {
+		if pre, ok := text.(util.Preformatted); ok {
+			found = true
+			if string(pre) != code {
+				t.Errorf("whitespace wasn't kept: %q", pre)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no preformatted text in %q", article.Paragraphs())
+	}
+	if want := "```\n" + code + "\n```"; !strings.Contains(article.Markdown(), want) {
+		t.Errorf("markdown doesn't contain the code block:\n%s", article.Markdown())
+	}
+}
+
+func TestExtractKeepEmphasis(t *testing.T) {
+	page := strings.Replace(testPage, "several major roads. Officials said",
+		`several <strong>major roads</strong>. <a href="/officials">Officials</a> <em>said</em>`, 1)
+	ext := NewExtractor()
+	ext.KeepInlineLinks = true
+	ext.KeepEmphasis = true
+	_, article := extractTestPage(t, ext, page)
+	para, ok := article.Text[1].(util.LinkedParagraph)
+	if !ok {
+		t.Fatalf("expected linked paragraph, got %T", article.Text[1])
+	}
+	if len(para.Strong) != 2 || para.Strong[0] != "major roads" || para.Strong[1] != "said" {
+		t.Errorf("unexpected emphasized texts %q", para.Strong)
+	}
+	if len(para.Links) != 1 || para.Links[0].URL != "/officials" {
+		t.Errorf("unexpected links %v", para.Links)
+	}
+	if want := "several **major roads**. [Officials](/officials) **said**"; !strings.Contains(article.Markdown(), want) {
+		t.Errorf("markdown doesn't contain %q:\n%s", want, article.Markdown())
+	}
+}
+
+func TestExtractParagraphs(t *testing.T) {
+	page := strings.Replace(testPage, "<p>Emergency crews", "<p>Emergency <b>crews</b>", 1)
+	doc, article := extractTestPage(t, NewExtractor(), page)
+	paragraphs := NewExtractor().ExtractParagraphs(doc)
+	if len(paragraphs) != 4 || paragraphs[0] != "Storm hits the coast" {
+		t.Fatalf("got paragraphs %q", paragraphs)
+	}
+	if !strings.HasPrefix(paragraphs[2], "Emergency crews worked through the night") {
+		t.Errorf("chunks of a block weren't joined: %q", paragraphs[2])
+	}
+
+	// The paragraphs must match the boundaries of the plain text output.
+	var buf bytes.Buffer
+	article.WriteTo(&buf)
+	if got := strings.Join(paragraphs, "\n\n") + "\n\n"; got != buf.String() {
+		t.Errorf("paragraphs %q don't match output %q", got, buf.String())
+	}
+
+	empty, err := html.NewDocument(strings.NewReader("<html><body></body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paragraphs := NewExtractor().ExtractParagraphs(empty); paragraphs != nil {
+		t.Errorf("got paragraphs %q for empty document", paragraphs)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	doc, article := extractTestPage(t, NewExtractor(), testPage)
+	for _, n := range []int{1, 3, 1000} {
+		if got, want := Summary(doc, n), article.Summary(n); got != want || got == "" {
+			t.Errorf("Summary(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if got, all := Summary(doc, 1), Summary(doc, 1000); len(got) >= len(all) {
+		t.Errorf("summary of one sentence %q isn't shorter than %q", got, all)
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	page := strings.Replace(testPage, "</title>", `</title>
+	<meta name="author" content="Jane Doe">
+	<meta property="article:published_time" content="2014-03-05T10:20:30Z">
+	<meta property="og:image" content="http://example.com/lead.jpg">`, 1)
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if article.Author != "Jane Doe" || article.Image != "http://example.com/lead.jpg" {
+		t.Errorf("got author %q and image %q", article.Author, article.Image)
+	}
+	if want := time.Date(2014, 3, 5, 10, 20, 30, 0, time.UTC); !article.Published.Equal(want) {
+		t.Errorf("got date %v", article.Published)
+	}
+}
+
+func TestIsArticle(t *testing.T) {
+	index := "<html><head><title>News</title></head><body>"
+	for _, topic := range []string{"Storm", "Election", "Football", "Markets", "Science", "Travel", "Health", "Music"} {
+		index += `<div class="teaser">
+			<h2><a href="/` + topic + `">` + topic + ` news of the day</a></h2>
+			<p>` + topic + ` reporters summarize what happened today in a short teaser text.</p>
+		</div>`
+	}
+	index += "</body></html>"
+
+	tests := []struct {
+		page string
+		want bool
+	}{
+		{testPage, true},
+		{index, false},
+	}
+	for _, test := range tests {
+		doc, err := html.NewDocument(strings.NewReader(test.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := NewExtractor().IsArticle(doc); got != test.want {
+			t.Errorf("IsArticle() = %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestIsArticleMinSentences(t *testing.T) {
+	// Both pages have about 60 words in a single container.
+	article := `<html><body><div class="recommended">`
+	for i := 0; i < 4; i++ {
+		article += "<p>The storm swept across the northern coast on Tuesday night. " +
+			"Crews worked hard to restore the power supply.</p>"
+	}
+	article += "</div></body></html>"
+	list := `<html><body><ul class="recommended">`
+	for i := 0; i < 12; i++ {
+		list += `<li><a href="/story">Storm hits northern coast today</a></li>`
+	}
+	list += "</ul></body></html>"
+
+	tests := []struct {
+		page         string
+		minSentences int
+		want         bool
+	}{
+		{article, DefaultMinSentences, true},
+		{list, DefaultMinSentences, false},
+		{list, 0, true},
+	}
+	for _, test := range tests {
+		doc, err := html.NewDocument(strings.NewReader(test.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext := NewExtractor()
+		ext.Scorer = recommendedScorer{}
+		ext.MinSentences = test.minSentences
+		if got := ext.IsArticle(doc); got != test.want {
+			t.Errorf("IsArticle() = %v with MinSentences %d, want %v", got, test.minSentences, test.want)
+		}
+	}
+}
+
+func TestContentRatio(t *testing.T) {
+	clutter := `<div class="links"><ul>`
+	for _, topic := range []string{"World", "Politics", "Business", "Technology", "Science", "Health", "Sports", "Travel"} {
+		clutter += `<li><a href="/` + topic + `">` + topic + ` news, opinion and analysis from around the globe</a></li>`
+	}
+	clutter += `</ul></div>`
+	cluttered := strings.Replace(testPage, "<article>", clutter+clutter+"<article>", 1)
+
+	ratio := func(page string) float32 {
+		doc, err := html.NewDocument(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewExtractor().ContentRatio(doc)
+	}
+	clean, low := ratio(testPage), ratio(cluttered)
+	if clean < 0.8 || clean > 1.0 {
+		t.Errorf("got ratio %f for clean article", clean)
+	}
+	if low > 0.5 || low >= clean {
+		t.Errorf("got ratio %f for cluttered page", low)
+	}
+	if empty := ratio("<html><body></body></html>"); empty != 0 {
+		t.Errorf("got ratio %f for empty page", empty)
+	}
+}
+
+func TestContentNodeTie(t *testing.T) {
+	page := "<html><body>"
+	for _, id := range []string{"first", "second"} {
+		page += `<div class="recommended" id="` + id + `">
+			<p>The storm swept across the northern coast on Tuesday night.</p>
+			<p>Crews worked hard to restore the power supply.</p>
+		</div>`
+	}
+	page += "</body></html>"
+
+	// Map iteration order varies, so a tie has to be decided the same way
+	// on every run.
+	for i := 0; i < 50; i++ {
+		doc, err := html.NewDocument(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext := NewExtractor()
+		ext.Scorer = recommendedScorer{}
+		node := ext.ContentNode(doc)
+		if node == nil || len(node.Attr) != 2 || node.Attr[1].Val != "first" {
+			t.Fatalf("ContentNode() = %v, want the first container", node)
+		}
+	}
+}
+
+func TestContentNode(t *testing.T) {
+	div := strings.Replace(testPage, "<article>", `<div class="story">`, 1)
+	div = strings.Replace(div, "</article>", "</div>", 1)
+
+	tests := []struct {
+		page string
+		want string
+	}{
+		{testPage, "article"},
+		{div, "div"},
+	}
+	for _, test := range tests {
+		doc, err := html.NewDocument(strings.NewReader(test.page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		node := NewExtractor().ContentNode(doc)
+		if node == nil || node.Data != test.want {
+			t.Errorf("ContentNode() = %v, want <%s>", node, test.want)
+			continue
+		}
+		for _, chunk := range doc.Chunks {
+			if strings.HasPrefix(chunk.Text.String(), "Emergency crews") && chunk.Container != node {
+				t.Errorf("ContentNode() doesn't contain the article text")
+			}
+		}
+	}
+
+	doc, err := html.NewDocument(strings.NewReader("<html><body></body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node := NewExtractor().ContentNode(doc); node != nil {
+		t.Errorf("ContentNode() = %v, want nil", node)
+	}
+}
+
+func TestExtractReuse(t *testing.T) {
+	small := strings.Replace(testPage, "<p>Emergency", "<p>Short paragraph.</p><p>Emergency", 1)
+	ext := NewExtractor()
+	for _, page := range []string{small, testPage, small} {
+		_, got := extractTestPage(t, ext, page)
+		_, want := extractTestPage(t, NewExtractor(), page)
+		if len(got.Text) != len(want.Text) {
+			t.Fatalf("got %d texts, want %d", len(got.Text), len(want.Text))
+		}
+		for i := range got.Text {
+			if got.Text[i] != want.Text[i] {
+				t.Errorf("text %d differs: %v, %v", i, got.Text[i], want.Text[i])
+			}
+		}
+	}
+}
+
+func BenchmarkExtract(b *testing.B) {
+	doc, err := html.NewDocument(strings.NewReader(testPage))
+	if err != nil {
+		b.Fatal(err)
+	}
+	ext := NewExtractor()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ext.Extract(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// recommendedScorer is a Scorer favoring chunks of the class "recommended".
+type recommendedScorer struct {
+	ModelScorer
+}
+
+func (s recommendedScorer) Score(chunk *html.Chunk, ctx ScoreContext) float32 {
+	for _, class := range chunk.Classes {
+		if class == "recommended" {
+			return 1.0
+		}
+	}
+	return s.ModelScorer.Score(chunk, ctx)
+}
+
+func TestExtractScorer(t *testing.T) {
+	page := strings.Replace(testPage, "<aside>", `<aside class="recommended">`, 1)
+
+	_, article := extractTestPage(t, NewExtractor(), page)
+	if contains(article, "Read another story here") {
+		t.Errorf("related links extracted by default")
+	}
+
+	ext := NewExtractor()
+	ext.Scorer = recommendedScorer{}
+	ext.MaxLinkDensity = 0
+	_, article = extractTestPage(t, ext, page)
+	if !contains(article, "Read another story here") || !contains(article, "Emergency crews") {
+		t.Errorf("unexpected result %v", article.Text)
+	}
+}
+
+// constScorer assigns the same score to all chunks.
+type constScorer float32
+
+func (s constScorer) Score(chunk *html.Chunk, ctx ScoreContext) float32 {
+	return float32(s)
+}
+
+func TestExtractPositionWeight(t *testing.T) {
+	page := "<html><body>"
+	for _, text := range []string{"Header", "First", "Second", "Third", "Footer"} {
+		page += "<div><p>" + text + " paragraph of a borderline page.</p></div>"
+	}
+	page += "</body></html>"
+
+	tests := []struct {
+		weight float32
+		want   []string
+	}{
+		{0.0, nil},
+		{0.2, []string{"First", "Second", "Third"}},
+		{1.0, []string{"First", "Second", "Third"}},
+	}
+	for _, test := range tests {
+		doc, err := html.NewDocument(strings.NewReader(page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext := NewExtractor()
+		ext.Scorer = constScorer(0.45)
+		ext.PositionWeight = test.weight
+		got := make([]string, 0)
+		if article, err := ext.Extract(doc); err == nil {
+			for _, text := range article.Text {
+				got = append(got, strings.Fields(fmt.Sprint(text))[0])
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("weight %.1f: got %q, want %q", test.weight, got, test.want)
+		}
+	}
+}
+
+// densityScorer records the link densities the extractor passes.
+type densityScorer struct {
+	ModelScorer
+	density map[string]float32
+}
+
+func (s densityScorer) Score(chunk *html.Chunk, ctx ScoreContext) float32 {
+	s.density[chunk.Text.String()] = ctx.LinkDensity
+	return s.ModelScorer.Score(chunk, ctx)
+}
+
+func TestExtractWordLinkDensity(t *testing.T) {
+	page := strings.Replace(testPage, `<li><a href="/world">World</a></li>`,
+		`<li><a href="/wirtschaft">Wirtschaftsnachrichten</a> and economy</li>`, 1)
+	page = strings.Replace(page, `<a href="/sports">Sports</a>`,
+		`<a href="/sports"><b>Sports</b> <span>news</span></a>`, 1)
+
+	tests := []struct {
+		words bool
+		min   float32
+		max   float32
+	}{
+		{false, 0.6, 0.7}, // 22 of 33 letters
+		{true, 0.3, 0.4},  // 1 of 3 words
+	}
+	for _, test := range tests {
+		scorer := densityScorer{density: make(map[string]float32)}
+		ext := NewExtractor()
+		ext.Scorer = scorer
+		ext.WordLinkDensity = test.words
+		extractTestPage(t, ext, page)
+		if d := scorer.density["Wirtschaftsnachrichten"]; d < test.min || d > test.max {
+			t.Errorf("WordLinkDensity %v: link density %f", test.words, d)
+		}
+		if d := scorer.density["and economy"]; d < test.min || d > test.max {
+			t.Errorf("WordLinkDensity %v: link density %f", test.words, d)
+		}
+		if d := scorer.density["Politics"]; d != 1.0 {
+			t.Errorf("WordLinkDensity %v: link density %f", test.words, d)
+		}
+		if d := scorer.density["Sports news"]; d != 1.0 {
+			t.Errorf("WordLinkDensity %v: nested link markup: link density %f", test.words, d)
+		}
+	}
+}
+
+func TestInLink(t *testing.T) {
+	doc, err := html.NewDocument(strings.NewReader(`<html><body><p>See <a href="/sports"><b>Sports</b></a></p></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Chunks) != 2 {
+		t.Fatalf("unexpected chunks %d", len(doc.Chunks))
+	}
+	if inLink(doc.Chunks[0]) || !inLink(doc.Chunks[1]) {
+		t.Errorf("inLink() = %v, %v", inLink(doc.Chunks[0]), inLink(doc.Chunks[1]))
+	}
+
+	// Chunks created from the text inside of a link's inline markup have the
+	// markup as base.
+	nested := *doc.Chunks[1]
+	nested.Base = nested.Base.FirstChild
+	if nested.Base.Data != "b" || !inLink(&nested) {
+		t.Errorf("link text wrapped in <%s> wasn't detected", nested.Base.Data)
+	}
+}
+
+func TestExtractMaxLinkDensity(t *testing.T) {
+	grid := `<div class="more-stories">`
+	for _, topic := range []string{"Flooding", "Evacuation", "Power outages", "Road closures"} {
+		grid += `<div class="teaser"><a href="/` + topic + `">` + topic + ` along the northern coast
+			continue as the storm moves further inland</a> (3 min)</div>`
+	}
+	grid += `</div>`
+	page := strings.Replace(testPage, "</article>", "</article>"+grid, 1)
+
+	// Favor the grid, so it would win without the cutoff.
+	page = strings.Replace(page, `class="teaser"`, `class="teaser recommended"`, -1)
+	ext := NewExtractor()
+	ext.Scorer = recommendedScorer{}
+	ext.MaxLinkDensity = 0
+	_, article := extractTestPage(t, ext, page)
+	if !contains(article, "Evacuation") {
+		t.Fatalf("grid wasn't extracted without cutoff")
+	}
+
+	ext.MaxLinkDensity = DefaultMaxLinkDensity
+	_, article = extractTestPage(t, ext, page)
+	if contains(article, "Evacuation") {
+		t.Errorf("grid was extracted despite cutoff")
+	}
+	if !contains(article, "Emergency crews") || !contains(article, "Storm hits the coast") {
+		t.Errorf("article text is missing")
+	}
+}
+
+func TestWriteDebug(t *testing.T) {
+	page := strings.Replace(testPage, "<p>A powerful storm", `<p id="storm" class="lead text">A powerful storm`, 1)
+	doc, err := html.NewDocument(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewExtractor().WriteDebug(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[0] != "score\tchunks\twords\tsentences\tlinkdensity\tnode" {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	// One line per block: the heading, three paragraphs and five links.
+	if len(lines) != 10 {
+		t.Fatalf("got %d lines, want 10:\n%s", len(lines), buf.String())
+	}
+	if want := "\t1\t26\t2\t0.000\tp#storm.lead.text"; !strings.HasSuffix(lines[1], want) {
+		t.Errorf("winning cluster %q doesn't end with %q", lines[1], want)
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		score, err := strconv.ParseFloat(fields[0], 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (fields[5] == "li") != (score < 0.5) {
+			t.Errorf("unexpected score %s of %s", fields[0], fields[5])
+		}
+	}
+}
+
+// prefixScorer assigns low scores to chunks starting with "Note" and high
+// scores to all other chunks.
+type prefixScorer struct{}
+
+func (s prefixScorer) Score(chunk *html.Chunk, ctx ScoreContext) float32 {
+	if strings.HasPrefix(chunk.Text.String(), "Note") {
+		return 0.2
+	}
+	return 0.9
+}
+
+func TestExtractKeepSections(t *testing.T) {
+	page := "<html><body><article>"
+	for _, name := range []string{"First", "Second", "Third"} {
+		page += "<section><h2>" + name + " part</h2>" +
+			"<p>The " + name + " part of a long article describes the storm and its aftermath in detail.</p>" +
+			"<p>Note on the " + name + " part.</p></section>"
+	}
+	page += "</article><section><p>Note one.</p><p>Note two.</p><p>Third short comment.</p></section></body></html>"
+
+	for _, keep := range []bool{false, true} {
+		ext := NewExtractor()
+		ext.Scorer = prefixScorer{}
+		ext.KeepSections = keep
+		_, article := extractTestPage(t, ext, page)
+		paragraphs := article.Paragraphs()
+		notes := 0
+		for _, text := range paragraphs {
+			if strings.HasPrefix(text, "Note on") {
+				notes++
+			}
+			if strings.HasPrefix(text, "Note one") || strings.HasPrefix(text, "Note two") {
+				t.Errorf("keep %v: comment %q extracted", keep, text)
+			}
+		}
+		if want := map[bool]int{false: 0, true: 3}[keep]; notes != want {
+			t.Errorf("keep %v: got %d notes, want %d in %q", keep, notes, want, paragraphs)
+		}
+	}
+}
@@ -137,10 +137,10 @@ func (fw *chunkFeatureWriter) WriteAncestors(chunk *html.Chunk) {
 	fw.Write((chunk.Ancestors & html.AncestorList) != 0)
 }
 
-func (fw *chunkFeatureWriter) WriteTextStat(chunk *html.Chunk) {
+func (fw *chunkFeatureWriter) WriteTextStat(chunk *html.Chunk, linkDensity float32) {
 	fw.Write(chunk.Text.Words)
 	fw.Write(chunk.Text.Sentences)
-	fw.Write(chunk.LinkText)
+	fw.Write(linkDensity)
 }
 
 func (fw *chunkFeatureWriter) WriteTextStatSiblings(chunk *html.Chunk) {
@@ -233,14 +233,14 @@ var (
 	)
 )
 
-func (fw *boostFeatureWriter) WriteChunk(chunk *html.Chunk) {
+func (fw *boostFeatureWriter) WriteChunk(chunk *html.Chunk, linkDensity float32) {
 	goodQual := false
 	poorQual := false
 	for _, class := range chunk.Classes {
 		goodQual = goodQual || goodQualClass.In(class)
 		poorQual = poorQual || poorQualClass.In(class)
 	}
-	fw.Write(chunk.LinkText)
+	fw.Write(linkDensity)
 	fw.Write(chunk.Text.Words)
 	fw.Write(chunk.Text.Sentences)
 	fw.Write(goodQual)
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/slyrz/newscat/html"
+)
+
+// ScoreContext contains the information available when scoring a chunk.
+type ScoreContext struct {
+	Doc         *html.Document            // document containing the chunk
+	Prev        *html.Chunk               // previous chunk or nil
+	Next        *html.Chunk               // next chunk or nil
+	LinkDensity float32                   // link text ratio of the chunk's block
+	Ancestors   int                       // bitmask of the chunk's ancestors
+	ClassStats  map[string]*html.TextStat // text stats of the document's classes
+	ModelScore  float32                   // score of the trained model
+}
+
+// A Scorer calculates the relevance score of a chunk. Scores range from
+// 0 to 1. The scores of all chunks in a block are averaged and chunks of
+// blocks scoring above 0.5 are considered relevant.
+type Scorer interface {
+	Score(chunk *html.Chunk, ctx ScoreContext) float32
+}
+
+// ModelScorer is the default Scorer. It returns the score of the trained
+// model. Custom Scorers can embed it to adjust the model's scores.
+type ModelScorer struct{}
+
+func (ModelScorer) Score(chunk *html.Chunk, ctx ScoreContext) float32 {
+	return ctx.ModelScore
+}
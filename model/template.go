@@ -0,0 +1,66 @@
+package model
+
+import (
+	"github.com/slyrz/newscat/html"
+)
+
+// DefaultTemplateShare is the default MinShare of TemplateDetectors.
+const DefaultTemplateShare = 0.5
+
+// templateMinDocuments is the number of documents a TemplateDetector needs
+// before it considers any text boilerplate.
+const templateMinDocuments = 2
+
+// A TemplateDetector finds the boilerplate of a site's template, like
+// headers, footers and navigation, by comparing several documents of the
+// same site. Texts found on most of the documents are part of the template
+// rather than the content.
+type TemplateDetector struct {
+	// MinShare is the share of documents a text must appear on to be
+	// boilerplate. The text must appear on more than MinShare of them.
+	// Zero means DefaultTemplateShare.
+	MinShare float32
+
+	// Unexported fields.
+	counts    map[string]int // number of documents containing each text
+	documents int            // number of documents added
+}
+
+// NewTemplateDetector creates a TemplateDetector with default settings.
+func NewTemplateDetector() *TemplateDetector {
+	return &TemplateDetector{
+		MinShare: DefaultTemplateShare,
+		counts:   make(map[string]int),
+	}
+}
+
+// Add adds the texts of the chunks of doc to the detector. Texts appearing
+// several times in doc count once.
+func (td *TemplateDetector) Add(doc *html.Document) {
+	if td.counts == nil {
+		td.counts = make(map[string]int)
+	}
+	seen := make(map[string]bool, len(doc.Chunks))
+	for _, chunk := range doc.Chunks {
+		text := chunk.Text.String()
+		if !seen[text] {
+			seen[text] = true
+			td.counts[text]++
+		}
+	}
+	td.documents++
+}
+
+// IsBoilerplate returns true if the text of chunk appears on more than
+// MinShare of the documents added so far. With fewer than two documents,
+// nothing is boilerplate.
+func (td *TemplateDetector) IsBoilerplate(chunk *html.Chunk) bool {
+	if td.documents < templateMinDocuments {
+		return false
+	}
+	share := td.MinShare
+	if share == 0 {
+		share = DefaultTemplateShare
+	}
+	return float32(td.counts[chunk.Text.String()]) > share*float32(td.documents)
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"github.com/slyrz/newscat/html"
+	"github.com/slyrz/newscat/util"
+	"strings"
+	"testing"
+)
+
+func TestTemplateDetector(t *testing.T) {
+	const footer = `<div class="recommended"><p>Example News is published by Example Media.
+		All rights reserved. Contact our newsroom for corrections.</p></div>`
+	stories := []string{
+		"A powerful storm swept across the northern coast on Tuesday, knocking out power to thousands of homes.",
+		"The city council approved the new budget on Wednesday after a long and heated debate.",
+		"The local football club won the championship for the first time in twenty years.",
+	}
+	docs := make([]*html.Document, len(stories))
+	for i, story := range stories {
+		doc, err := html.NewDocument(strings.NewReader(
+			`<html><body><article class="recommended"><p>` + story + `</p></article>` + footer + `</body></html>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs[i] = doc
+	}
+
+	// The zero value works like a detector with default settings.
+	zero := &TemplateDetector{}
+	for _, doc := range docs {
+		zero.Add(doc)
+	}
+	for _, chunk := range docs[0].Chunks {
+		want := strings.HasPrefix(chunk.Text.String(), "Example News")
+		if got := zero.IsBoilerplate(chunk); got != want {
+			t.Errorf("zero value: IsBoilerplate(%q) = %v, want %v", chunk.Text, got, want)
+		}
+	}
+
+	td := NewTemplateDetector()
+	td.Add(docs[0])
+	for _, chunk := range docs[0].Chunks {
+		if td.IsBoilerplate(chunk) {
+			t.Errorf("chunk %q is boilerplate of a single document", chunk.Text)
+		}
+	}
+	td.Add(docs[1])
+	td.Add(docs[2])
+	for _, chunk := range docs[2].Chunks {
+		want := strings.HasPrefix(chunk.Text.String(), "Example News")
+		if got := td.IsBoilerplate(chunk); got != want {
+			t.Errorf("IsBoilerplate(%q) = %v, want %v", chunk.Text, got, want)
+		}
+	}
+
+	ext := NewExtractor()
+	ext.Scorer = recommendedScorer{}
+	for i, doc := range docs {
+		ext.Template = nil
+		article, err := ext.Extract(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(article.Text) != 2 {
+			t.Errorf("page %d: footer wasn't extracted without template", i)
+		}
+		ext.Template = td
+		if article, err = ext.Extract(doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(article.Text) != 1 || article.Text[0] != util.Paragraph(stories[i]) {
+			t.Errorf("page %d: got %q", i, article.Text)
+		}
+	}
+}
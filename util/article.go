@@ -1,11 +1,182 @@
 package util
 
-type Heading string
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultWordsPerMinute is the reading speed used by Article.ReadingTime.
+const DefaultWordsPerMinute = 200
+
+// Heading is a heading of level 1 (most important) to 6. Heading used to be
+// a string type; callers converting it to or from a string must now use the
+// Text field, e.g. Heading{Level: 1, Text: s}.
+type Heading struct {
+	Level int
+	Text  string
+}
+
 type Paragraph string
 
+// Quote is a paragraph found inside of a block quotation.
+type Quote string
+
+// ListItem is an item of a list. Consecutive items belong to the same list.
+type ListItem string
+
+// Preformatted is text whose whitespace matters, like source code. It keeps
+// its line breaks and indentation.
+type Preformatted string
+
+// A Link is a hyperlink found inside of a paragraph.
+type Link struct {
+	Text     string
+	URL      string
+	Context  string // text of the nearest heading before the link, if known
+	Target   string // browsing context the link opens in, e.g. "_blank"
+	Download bool   // link has a download attribute
+	FeedType string // "rss", "atom" or "json" for feeds of known type
+	Rel      string // space separated lower case link types, e.g. "nofollow"
+}
+
+// HasRel returns true if rel is one of the link types in l.Rel. Link types
+// are compared case-insensitively.
+func (l Link) HasRel(rel string) bool {
+	for _, val := range strings.Fields(l.Rel) {
+		if strings.EqualFold(val, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// feedSegments are path segments and extensions of feed URLs.
+var feedSegments = map[string]bool{
+	"atom":  true,
+	"feed":  true,
+	"feeds": true,
+	"rss":   true,
+	"rss2":  true,
+}
+
+// IsFeed returns true if the URL of the link looks like the URL of an RSS or
+// Atom feed, e.g. /feed, /rss/world.xml, /news.atom or /?format=rss. XML
+// files named like sitemaps don't count.
+func (l Link) IsFeed() bool {
+	u, err := url.Parse(strings.TrimSpace(l.URL))
+	if err != nil {
+		return false
+	}
+	p := strings.ToLower(u.Path)
+	switch path.Ext(p) {
+	case ".rss", ".atom":
+		return true
+	case ".xml":
+		return !strings.Contains(path.Base(p), "sitemap")
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if feedSegments[segment] {
+			return true
+		}
+	}
+	for _, key := range []string{"feed", "format", "type"} {
+		if feedSegments[strings.ToLower(u.Query().Get(key))] {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalized returns the URL of the link in a form that is equal for URLs
+// pointing to the same page. The scheme and host are lower case, default
+// ports, fragments and trailing slashes are removed and the query
+// parameters are sorted. URLs that can't be parsed are returned as they are.
+func (l Link) Normalized() string {
+	s := strings.TrimSpace(l.URL)
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	// Encode sorts the parameters by key.
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
+
+// LinkedParagraph is a paragraph containing hyperlinks or emphasized text.
+// The links and emphasized parts are in order of appearance.
+type LinkedParagraph struct {
+	Text   string
+	Links  []Link
+	Strong []string // emphasized parts of the text
+}
+
+func (p LinkedParagraph) String() string {
+	return p.Text
+}
+
+// split calls fn for each part of the paragraph's text in order. Parts
+// which are the text of one of the paragraph's links get passed along with
+// their link, emphasized parts with strong set, all other parts with a nil
+// link.
+func (p LinkedParagraph) split(fn func(text string, link *Link, strong bool)) {
+	rest := p.Text
+	emit := func(j int, s string, link *Link, strong bool) {
+		if j > 0 {
+			fn(rest[:j], nil, false)
+		}
+		fn(s, link, strong)
+		rest = rest[j+len(s):]
+	}
+	links, strong := p.Links, p.Strong
+	for len(links) > 0 || len(strong) > 0 {
+		li, si := -1, -1
+		if len(links) > 0 && links[0].Text != "" {
+			li = strings.Index(rest, links[0].Text)
+		}
+		if len(strong) > 0 && strong[0] != "" {
+			si = strings.Index(rest, strong[0])
+		}
+		switch {
+		case len(links) > 0 && li < 0:
+			links = links[1:]
+		case len(strong) > 0 && si < 0:
+			strong = strong[1:]
+		case len(strong) == 0 || (len(links) > 0 && li <= si):
+			emit(li, links[0].Text, &links[0], false)
+			links = links[1:]
+		default:
+			emit(si, strong[0], nil, true)
+			strong = strong[1:]
+		}
+	}
+	if rest != "" {
+		fn(rest, nil, false)
+	}
+}
+
+func (h Heading) String() string {
+	return h.Text
+}
+
 type Article struct {
-	Title string
-	Text  []interface{}
+	Title     string
+	Text      []interface{}
+	Author    string    // name of the author, if known
+	Published time.Time // publication date or the zero time if unknown
+	Image     string    // URL of the lead image, if known
 }
 
 func (a *Article) Append(v interface{}) {
@@ -23,3 +194,123 @@ func (a *Article) StartsWithHeading() bool {
 	_, ok := a.Text[0].(Heading)
 	return ok
 }
+
+// text returns the combined text of the article.
+func (a *Article) text() *Text {
+	text := NewText()
+	for _, v := range a.Text {
+		switch v := v.(type) {
+		case Heading:
+			text.WriteString(v.Text)
+		case Paragraph:
+			text.WriteString(string(v))
+		case Quote:
+			text.WriteString(string(v))
+		case ListItem:
+			text.WriteString(string(v))
+		case Preformatted:
+			text.WriteString(string(v))
+		case LinkedParagraph:
+			text.WriteString(v.Text)
+		}
+	}
+	return text
+}
+
+// Words returns the number of words in the article's text, including its
+// headings but not its title.
+func (a *Article) Words() int {
+	return a.text().Words
+}
+
+// Sentences returns the number of sentences in the article's text,
+// including its headings but not its title or preformatted texts. Like in
+// Summary, each text is counted on its own and text without final
+// punctuation counts as one sentence.
+func (a *Article) Sentences() int {
+	count := 0
+	for _, text := range a.Text {
+		if _, ok := text.(Preformatted); ok {
+			continue
+		}
+		_, n := firstSentences(fmt.Sprint(text), 0)
+		count += n
+	}
+	return count
+}
+
+// ReadingTime returns the estimated time it takes to read the article at a
+// speed of DefaultWordsPerMinute.
+func (a *Article) ReadingTime() time.Duration {
+	return a.ReadingTimeWPM(DefaultWordsPerMinute)
+}
+
+// ReadingTimeWPM returns the estimated time it takes to read the article at a
+// speed of wpm words per minute.
+func (a *Article) ReadingTimeWPM(wpm int) time.Duration {
+	if wpm <= 0 {
+		return 0
+	}
+	return time.Duration(a.Words()) * time.Minute / time.Duration(wpm)
+}
+
+// Paragraphs returns the texts of the article, one string per heading,
+// paragraph, quote or list item, as they are printed by WriteTo.
+func (a *Article) Paragraphs() []string {
+	result := make([]string, len(a.Text))
+	for i, text := range a.Text {
+		result[i] = fmt.Sprint(text)
+	}
+	return result
+}
+
+// Summary returns the first maxSentences sentences of the article's text
+// joined by spaces, which is meant for previews. Headings and preformatted
+// texts are left out and paragraphs, quotes and list items without final
+// punctuation count as one sentence. If the article has fewer sentences, Summary returns all of them.
+func (a *Article) Summary(maxSentences int) string {
+	result := make([]string, 0)
+	for _, text := range a.Text {
+		if maxSentences <= 0 {
+			break
+		}
+		switch text.(type) {
+		case Heading, Preformatted:
+			continue
+		}
+		s, n := firstSentences(fmt.Sprint(text), maxSentences)
+		if s != "" {
+			result = append(result, s)
+			maxSentences -= n
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+// WriteTo writes the article as plain text to w. Every heading and paragraph
+// is followed by a blank line. It returns the number of bytes written.
+func (a *Article) WriteTo(w io.Writer) (int64, error) {
+	return a.writeText(w, false)
+}
+
+// WriteHighlighted works like WriteTo, but prints headings in bold using
+// ANSI escape sequences. This is meant for terminals.
+func (a *Article) WriteHighlighted(w io.Writer) (int64, error) {
+	return a.writeText(w, true)
+}
+
+func (a *Article) writeText(w io.Writer, highlight bool) (int64, error) {
+	var total int64
+	for _, text := range a.Text {
+		pre, pos := "", ""
+		if _, ok := text.(Heading); ok && highlight {
+			pre, pos = "\x1b[1m", "\x1b[0m"
+		}
+		n, err := fmt.Fprintf(w, "%s%s%s\n\n", pre, text, pos)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
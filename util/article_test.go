@@ -0,0 +1,154 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadingTime(t *testing.T) {
+	short := &Article{}
+	short.Append(Heading{1, "Short News"})
+	short.Append(Paragraph("Nothing happened today."))
+	if got := short.ReadingTime(); got != 5*time.Minute/200 {
+		t.Errorf("unexpected reading time %v", got)
+	}
+
+	long := &Article{}
+	for i := 0; i < 100; i++ {
+		long.Append(Paragraph(strings.Repeat("word ", 10)))
+	}
+	if got := long.ReadingTime(); got != 5*time.Minute {
+		t.Errorf("unexpected reading time %v", got)
+	}
+	if got := long.ReadingTimeWPM(100); got != 10*time.Minute {
+		t.Errorf("unexpected reading time %v", got)
+	}
+	if got := long.ReadingTimeWPM(0); got != 0 {
+		t.Errorf("unexpected reading time %v", got)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	article := &Article{}
+	article.Append(Heading{1, "Hello World"})
+	article.Append(Paragraph("First paragraph."))
+	article.Append(ListItem("Item"))
+
+	var buf bytes.Buffer
+	n, err := article.WriteTo(&buf)
+	const want = "Hello World\n\nFirst paragraph.\n\nItem\n\n"
+	if err != nil || buf.String() != want || n != int64(len(want)) {
+		t.Errorf("WriteTo wrote %q, %d bytes, %v", buf.String(), n, err)
+	}
+
+	buf.Reset()
+	article.WriteHighlighted(&buf)
+	if got := buf.String(); got != "\x1b[1mHello World\x1b[0m\n\nFirst paragraph.\n\nItem\n\n" {
+		t.Errorf("WriteHighlighted wrote %q", got)
+	}
+}
+
+func TestArticleCounts(t *testing.T) {
+	article := &Article{Title: "Ignored title words"}
+	article.Append(Heading{1, "Storm hits coast"})
+	article.Append(Paragraph("The storm came at night. Nobody was hurt."))
+	article.Append(Quote("\"Stay indoors.\""))
+	article.Append(ListItem("Roads closed"))
+	article.Append(LinkedParagraph{Text: "Read the report.", Links: []Link{{Text: "report", URL: "/report"}}})
+
+	if got := article.Words(); got != 17 {
+		t.Errorf("Words() = %d", got)
+	}
+	// The heading and the list item lack final punctuation, but count as
+	// sentences of their own.
+	if got := article.Sentences(); got != 6 {
+		t.Errorf("Sentences() = %d", got)
+	}
+	article.Text = article.Text[1:]
+	if got, want := article.Summary(article.Sentences()), article.Summary(100); got != want {
+		t.Errorf("Summary(Sentences()) = %q, want %q", got, want)
+	}
+	if got := article.Summary(article.Sentences() - 1); strings.HasSuffix(got, "report.") {
+		t.Errorf("Summary(Sentences() - 1) = %q includes the last sentence", got)
+	}
+	if got := (&Article{}).Words(); got != 0 {
+		t.Errorf("Words() = %d for empty article", got)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	article := &Article{Text: []interface{}{
+		Heading{Level: 1, Text: "Storm hits the coast"},
+		Paragraph("A storm hit the coast. Officials said Mr. Smith was safe!"),
+		ListItem("Power is out"),
+		Quote("Stay indoors. It's dangerous."),
+		Paragraph("风暴来了。人们很安全。"),
+	}}
+	tests := []struct {
+		sentences int
+		want      string
+	}{
+		{0, ""},
+		{1, "A storm hit the coast."},
+		{2, "A storm hit the coast. Officials said Mr. Smith was safe!"},
+		{3, "A storm hit the coast. Officials said Mr. Smith was safe! Power is out"},
+		{5, "A storm hit the coast. Officials said Mr. Smith was safe! Power is out Stay indoors. It's dangerous."},
+		{6, "A storm hit the coast. Officials said Mr. Smith was safe! Power is out Stay indoors. It's dangerous. 风暴来了。"},
+		{100, "A storm hit the coast. Officials said Mr. Smith was safe! Power is out Stay indoors. It's dangerous. 风暴来了。人们很安全。"},
+	}
+	for _, test := range tests {
+		if got := article.Summary(test.sentences); got != test.want {
+			t.Errorf("Summary(%d) = %q, want %q", test.sentences, got, test.want)
+		}
+	}
+}
+
+func TestLinkNormalized(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://example.com/news/", "http://example.com/news"},
+		{"HTTP://Example.COM:80/news#top", "http://example.com/news"},
+		{"https://example.com:443/", "https://example.com"},
+		{"https://example.com:8443/News/", "https://example.com:8443/News"},
+		{"http://example.com/search?q=storm&a=1#results", "http://example.com/search?a=1&q=storm"},
+		{" /relative/path/ ", "/relative/path"},
+	}
+	for _, test := range tests {
+		if got := (Link{URL: test.url}).Normalized(); got != test.want {
+			t.Errorf("Normalized(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestLinkIsFeed(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"/feed", true},
+		{"/feed/", true},
+		{"https://example.com/news/feed/rss2", true},
+		{"/rss/world.xml", true},
+		{"/index.xml", true},
+		{"/news.rss", true},
+		{"/blog.ATOM", true},
+		{"/feeds/posts/default", true},
+		{"/?format=rss", true},
+		{"/index.php?feed=atom", true},
+		{"/sitemap.xml", false},
+		{"/sitemaps/news-sitemap.xml", false},
+		{"/news/feed-the-world", false},
+		{"/2014/storm.html", false},
+		{"/?format=print", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := (Link{URL: test.url}).IsFeed(); got != test.want {
+			t.Errorf("IsFeed(%q) = %v, want %v", test.url, got, test.want)
+		}
+	}
+}
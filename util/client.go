@@ -0,0 +1,92 @@
+package util
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultUserAgent is sent by Clients unless configured otherwise. Some
+	// sites refuse to serve requests with Go's default User-Agent.
+	DefaultUserAgent = "Mozilla/5.0 (compatible; newscat; +https://github.com/slyrz/newscat)"
+	// DefaultTimeout is the default time limit of requests made by Clients.
+	DefaultTimeout = 30 * time.Second
+
+	maxRedirects = 10
+)
+
+// Client is an HTTP client for fetching HTML pages.
+type Client struct {
+	http.Client
+	UserAgent string // value of the User-Agent header
+}
+
+// NewClient creates a new Client whose requests time out after timeout.
+// A timeout of zero means no timeout.
+func NewClient(timeout time.Duration, userAgent string) *Client {
+	client := &Client{UserAgent: userAgent}
+	client.Timeout = timeout
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+	return client
+}
+
+// Fetch sends a GET request to url. It returns an error if the request
+// fails or the server doesn't respond with a 2xx status code. Otherwise the
+// caller has to close the response body.
+func (c *Client) Fetch(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	// The transport only decompresses responses if it asked for compression
+	// itself, but some servers compress their responses regardless.
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		if resp.Uncompressed {
+			break
+		}
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %v", url, err)
+		}
+		resp.Body = &gzipBody{zr, resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	return resp, nil
+}
+
+// gzipBody decompresses a response body and closes it along with the
+// decompressor.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (gb *gzipBody) Close() error {
+	gb.Reader.Close()
+	return gb.body.Close()
+}
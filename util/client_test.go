@@ -0,0 +1,87 @@
+package util
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(r.UserAgent()))
+	}))
+	defer server.Close()
+
+	client := NewClient(DefaultTimeout, "test-agent")
+	resp, err := client.Fetch(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	n, _ := resp.Body.Read(buf)
+	resp.Body.Close()
+	if string(buf[:n]) != "test-agent" {
+		t.Errorf("unexpected User-Agent %q", buf[:n])
+	}
+
+	if _, err := client.Fetch(server.URL + "/missing"); err == nil {
+		t.Errorf("expected error for status 404")
+	}
+}
+
+func TestClientFetchGzip(t *testing.T) {
+	const page = "<html><body><p>Compressed</p></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Compress regardless of the Accept-Encoding header.
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(page))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	for _, disable := range []bool{false, true} {
+		client := NewClient(DefaultTimeout, "")
+		client.Transport = &http.Transport{DisableCompression: disable}
+		resp, err := client.Fetch(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != page {
+			t.Errorf("DisableCompression %v: got %q", disable, data)
+		}
+		if resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("DisableCompression %v: Content-Encoding wasn't removed", disable)
+		}
+	}
+}
+
+func TestOpenInputRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/x", http.RedirectHandler("/news/x", http.StatusFound))
+	mux.HandleFunc("/news/x", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	input, err := OpenInput(server.URL+"/x", NewClient(DefaultTimeout, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Data.Close()
+	if input.Origin != server.URL+"/x" || input.URL != server.URL+"/news/x" {
+		t.Errorf("got origin %q and URL %q", input.Origin, input.URL)
+	}
+}
@@ -2,12 +2,9 @@ package util
 
 import "hash/fnv"
 
-var (
-	hash = fnv.New32()
-)
-
+// Hash returns the 32-bit FNV-1 hash of s. It's safe for concurrent use.
 func Hash(s string) uint32 {
-	hash.Reset()
+	hash := fnv.New32()
 	hash.Write([]byte(s))
 	return hash.Sum32()
 }
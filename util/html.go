@@ -0,0 +1,60 @@
+package util
+
+import (
+	"bytes"
+	"html"
+	"strconv"
+)
+
+// HTML renders the article as fragment of semantic HTML. Headings become
+// <h1> to <h6> elements, paragraphs become <p> elements and quotes become
+// <blockquote> elements. Consecutive list items are wrapped in a single
+// <ul> element and preformatted texts become <pre> elements. Links of
+// linked paragraphs become <a> elements. All text is escaped.
+func (a *Article) HTML() string {
+	var buf bytes.Buffer
+	inList := false
+	for _, text := range a.Text {
+		if _, ok := text.(ListItem); ok != inList {
+			if ok {
+				buf.WriteString("<ul>\n")
+			} else {
+				buf.WriteString("</ul>\n")
+			}
+			inList = ok
+		}
+		switch text := text.(type) {
+		case Heading:
+			level := text.Level
+			if level < 1 || level > 6 {
+				level = 1
+			}
+			tag := "h" + strconv.Itoa(level)
+			buf.WriteString("<" + tag + ">" + html.EscapeString(text.Text) + "</" + tag + ">\n")
+		case Paragraph:
+			buf.WriteString("<p>" + html.EscapeString(string(text)) + "</p>\n")
+		case Quote:
+			buf.WriteString("<blockquote><p>" + html.EscapeString(string(text)) + "</p></blockquote>\n")
+		case ListItem:
+			buf.WriteString("<li>" + html.EscapeString(string(text)) + "</li>\n")
+		case Preformatted:
+			buf.WriteString("<pre>" + html.EscapeString(string(text)) + "</pre>\n")
+		case LinkedParagraph:
+			buf.WriteString("<p>")
+			text.split(func(s string, link *Link, strong bool) {
+				if link != nil {
+					buf.WriteString(`<a href="` + html.EscapeString(link.URL) + `">` + html.EscapeString(s) + "</a>")
+				} else if strong {
+					buf.WriteString("<strong>" + html.EscapeString(s) + "</strong>")
+				} else {
+					buf.WriteString(html.EscapeString(s))
+				}
+			})
+			buf.WriteString("</p>\n")
+		}
+	}
+	if inList {
+		buf.WriteString("</ul>\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		golden  string
+		article *Article
+	}{
+		{
+			"paragraphs.html",
+			&Article{Text: []interface{}{
+				Heading{Level: 1, Text: "Storm <hits> the coast"},
+				Paragraph("Officials said the damage was \"extensive\"."),
+				Heading{Level: 9, Text: "Invalid level"},
+				Paragraph("Crews & volunteers worked through the night."),
+			}},
+		},
+		{
+			"structure.html",
+			&Article{Text: []interface{}{
+				Heading{Level: 2, Text: "What we know"},
+				ListItem("Power is out."),
+				ListItem("Roads are closed."),
+				Quote("Stay indoors."),
+				Paragraph("More updates will follow."),
+				ListItem("Flooding remains possible."),
+				LinkedParagraph{
+					Text: "See the <official> map and more.",
+					Links: []Link{
+						{Text: "<official> map", URL: "/map?a=1&b=2"},
+						{Text: "more", URL: "http://example.com/\"more\""},
+					},
+				},
+				Preformatted("if a < b {\n\treturn\n}"),
+			}},
+		},
+	}
+	for _, test := range tests {
+		want, err := os.ReadFile(filepath.Join("testdata", test.golden))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := test.article.HTML(); got != string(want) {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.golden, got, want)
+		}
+	}
+}
+
+func TestReaderHTML(t *testing.T) {
+	article := &Article{
+		Title: "Storm <hits> the coast",
+		Text: []interface{}{
+			Paragraph("A powerful storm swept across the coast."),
+			ListItem("Power is out."),
+		},
+		Author:    "Jane Doe",
+		Published: time.Date(2014, 3, 5, 10, 20, 30, 0, time.UTC),
+		Image:     "http://example.com/lead.jpg?a=1&b=2",
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "reader.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := article.ReaderHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Without metadata, the byline and image are left out and headings of
+	// the text replace the title heading.
+	article = &Article{Title: "Storm", Text: []interface{}{Heading{Level: 1, Text: "Storm"}, Paragraph("Text.")}}
+	if got, err = article.ReaderHTML(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"byline", "<img", "<h1>Storm</h1>\n<h1>"} {
+		if strings.Contains(got, s) {
+			t.Errorf("unexpected %q in\n%s", s, got)
+		}
+	}
+}
@@ -2,33 +2,44 @@ package util
 
 import (
 	"io"
-	"net/http"
+	"mime"
 	"os"
 	"strings"
 )
 
 // Input stores the user-provided data and its origin.
 type Input struct {
-	Origin string        // either file path or URL or empty if data was read from stdin
-	Data   io.ReadCloser // the HTML data (hopefully)
+	Origin  string        // either file path or URL or empty if data was read from stdin
+	Data    io.ReadCloser // the HTML data (hopefully)
+	Charset string        // the charset declared by the HTTP response, if any
+	URL     string        // the URL of the data after following redirects, if fetched
 }
 
-func GetInput(args []string) []Input {
-	result := make([]Input, 0)
-	if len(args) > 0 {
-		for _, arg := range args {
-			if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
-				if resp, err := http.Get(arg); err == nil {
-					result = append(result, Input{arg, resp.Body})
-				}
-			} else {
-				if file, err := os.Open(arg); err == nil {
-					result = append(result, Input{arg, file})
-				}
-			}
+// GetCharset returns the charset parameter of the Content-Type header value.
+func GetCharset(contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		return params["charset"]
+	}
+	return ""
+}
+
+// OpenInput opens the file or fetches the URL arg using client. The
+// argument "-" denotes standard input.
+func OpenInput(arg string, client *Client) (Input, error) {
+	switch {
+	case arg == "-":
+		return Input{"", os.Stdin, "", ""}, nil
+	case strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://"):
+		resp, err := client.Fetch(arg)
+		if err != nil {
+			return Input{}, err
+		}
+		return Input{arg, resp.Body, GetCharset(resp.Header.Get("Content-Type")), resp.Request.URL.String()}, nil
+	default:
+		file, err := os.Open(arg)
+		if err != nil {
+			return Input{}, err
 		}
-	} else {
-		result = append(result, Input{"", os.Stdin})
+		return Input{arg, file, "", ""}, nil
 	}
-	return result
 }
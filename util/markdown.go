@@ -0,0 +1,95 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+)
+
+// markdownEscaper escapes characters which have a special meaning in
+// inline Markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+)
+
+// markdownURLEscaper escapes characters which would end the URL of a
+// Markdown link.
+var markdownURLEscaper = strings.NewReplacer(
+	`(`, `%28`,
+	`)`, `%29`,
+	` `, `%20`,
+)
+
+// escapeMarkdown escapes text, so it's rendered literally by Markdown.
+func escapeMarkdown(text string) string {
+	text = markdownEscaper.Replace(text)
+	// A leading hash sign or block quote marker would turn a paragraph into
+	// a heading or quote.
+	if strings.HasPrefix(text, "#") || strings.HasPrefix(text, ">") {
+		text = `\` + text
+	}
+	return text
+}
+
+// Markdown renders the article as Markdown document. Headings become ATX
+// headings of the same level, paragraphs are separated by blank lines.
+// Quotes become block quotes and consecutive list items form a single list.
+// Preformatted texts become fenced code blocks. Links of linked paragraphs
+// become inline links.
+func (a *Article) Markdown() string {
+	var buf bytes.Buffer
+	for i, text := range a.Text {
+		if i > 0 {
+			_, item := text.(ListItem)
+			_, prevItem := a.Text[i-1].(ListItem)
+			if !item || !prevItem {
+				buf.WriteString("\n")
+			}
+		}
+		switch text := text.(type) {
+		case Heading:
+			level := text.Level
+			if level < 1 || level > 6 {
+				level = 1
+			}
+			buf.WriteString(strings.Repeat("#", level))
+			buf.WriteString(" ")
+			buf.WriteString(escapeMarkdown(text.Text))
+		case Paragraph:
+			buf.WriteString(escapeMarkdown(string(text)))
+		case Quote:
+			buf.WriteString("> ")
+			buf.WriteString(escapeMarkdown(string(text)))
+		case ListItem:
+			buf.WriteString("- ")
+			buf.WriteString(escapeMarkdown(string(text)))
+		case Preformatted:
+			// The fence must be longer than any run of backticks in the text.
+			fence := "```"
+			for strings.Contains(string(text), fence) {
+				fence += "`"
+			}
+			buf.WriteString(fence + "\n" + string(text) + "\n" + fence)
+		case LinkedParagraph:
+			first := true
+			text.split(func(s string, link *Link, strong bool) {
+				if link != nil {
+					buf.WriteString("[" + markdownEscaper.Replace(s) + "](" + markdownURLEscaper.Replace(link.URL) + ")")
+				} else if strong {
+					buf.WriteString("**" + markdownEscaper.Replace(s) + "**")
+				} else if first {
+					buf.WriteString(escapeMarkdown(s))
+				} else {
+					buf.WriteString(markdownEscaper.Replace(s))
+				}
+				first = false
+			})
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
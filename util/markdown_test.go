@@ -0,0 +1,91 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	article := &Article{}
+	article.Append(Heading{1, "Hello World"})
+	article.Append(Paragraph("Some *special* [characters]."))
+	article.Append(Heading{3, "Section"})
+	article.Append(Paragraph("# not a heading"))
+
+	const want = "# Hello World\n" +
+		"\n" +
+		"Some \\*special\\* \\[characters\\].\n" +
+		"\n" +
+		"### Section\n" +
+		"\n" +
+		"\\# not a heading\n"
+
+	if got := article.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestMarkdownStructure(t *testing.T) {
+	article := &Article{}
+	article.Append(ListItem("First"))
+	article.Append(ListItem("Second"))
+	article.Append(Quote("Quoted *text*"))
+
+	const want = "- First\n" +
+		"- Second\n" +
+		"\n" +
+		"> Quoted \\*text\\*\n"
+
+	if got := article.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestMarkdownLinks(t *testing.T) {
+	article := &Article{}
+	article.Append(LinkedParagraph{
+		Text: "# Read the report and the *summary*.",
+		Links: []Link{
+			{Text: "report", URL: "http://example.com/report (2014)"},
+			{Text: "*summary*", URL: "/summary"},
+			{Text: "missing", URL: "/missing"},
+		},
+	})
+
+	const want = "\\# Read the [report](http://example.com/report%20%282014%29) and the [\\*summary\\*](/summary).\n"
+
+	if got := article.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestMarkdownStrong(t *testing.T) {
+	article := &Article{}
+	article.Append(LinkedParagraph{
+		Text:   "A *bold* claim with a source and more.",
+		Links:  []Link{{Text: "source", URL: "/source"}},
+		Strong: []string{"*bold*", "more"},
+	})
+
+	const want = "A **\\*bold\\*** claim with a [source](/source) and **more**.\n"
+
+	if got := article.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestMarkdownPreformatted(t *testing.T) {
+	article := &Article{}
+	article.Append(Paragraph("Run this:"))
+	article.Append(Preformatted("# not a heading\n\techo ```"))
+
+	const want = "Run this:\n" +
+		"\n" +
+		"````\n" +
+		"# not a heading\n" +
+		"\techo ```\n" +
+		"````\n"
+
+	if got := article.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
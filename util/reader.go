@@ -0,0 +1,55 @@
+package util
+
+import (
+	"bytes"
+	"html/template"
+	"time"
+)
+
+// readerTemplate is the HTML document rendered by ReaderHTML.
+var readerTemplate = template.Must(template.New("reader").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<article>
+{{if .Heading}}<h1>{{.Title}}</h1>
+{{end}}{{if or .Author .Date}}<p class="byline">{{with .Author}}By {{.}}{{end}}{{if and .Author .Date}} · {{end}}{{with .Date}}<time datetime="{{.Format "2006-01-02T15:04:05Z07:00"}}">{{.Format "January 2, 2006"}}</time>{{end}}</p>
+{{end}}{{with .Image}}<img src="{{.}}" alt="">
+{{end}}{{.Content}}</article>
+</body>
+</html>
+`))
+
+// ReaderHTML renders the article as complete HTML document for reading,
+// like the reader modes of browsers. The document contains the title, the
+// byline made of author and publication date, the lead image and the text
+// of the article as rendered by HTML. Parts which are unknown are left out.
+func (a *Article) ReaderHTML() (string, error) {
+	var date *time.Time
+	if !a.Published.IsZero() {
+		date = &a.Published
+	}
+	data := struct {
+		Title   string
+		Heading bool
+		Author  string
+		Date    *time.Time
+		Image   string
+		Content template.HTML
+	}{
+		Title:   a.Title,
+		Heading: a.Title != "" && !a.StartsWithHeading(),
+		Author:  a.Author,
+		Date:    date,
+		Image:   a.Image,
+		Content: template.HTML(a.HTML()),
+	}
+	var buf bytes.Buffer
+	if err := readerTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -2,8 +2,10 @@ package util
 
 import (
 	"bytes"
+	"html"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type Text struct {
@@ -20,25 +22,192 @@ func NewText() *Text {
 	return text
 }
 
-// isWord returns true if the passed text seems to be an actual word and not
-// clutter like email addresses or URLs.
-func isWord(text string) bool {
-	letters := 0
-	for _, rune := range text {
+// CountLetters returns the number of letters in s. This is how text gets
+// measured for scoring.
+func CountLetters(s string) int {
+	result := 0
+	for _, rune := range s {
 		if unicode.IsLetter(rune) {
-			letters += 1
+			result += 1
 		}
 	}
+	return result
+}
+
+// isWord returns true if the passed text seems to be an actual word and not
+// clutter like email addresses or URLs.
+func isWord(text string) bool {
+	letters := CountLetters(text)
 	// A "real" word must have more than 2 characters (sorry "it")
 	// and is only allowed to contain at most 2 non-letter characters.
 	return (len(text) > 2) && (letters >= (len(text) - 2))
 }
 
+// Abbreviations contains lower case abbreviations that don't end a sentence
+// although they end with a period. Add entries to support more languages.
+var Abbreviations = map[string]bool{
+	"approx.": true,
+	"capt.":   true,
+	"cf.":     true,
+	"col.":    true,
+	"dr.":     true,
+	"e.g.":    true,
+	"fig.":    true,
+	"gen.":    true,
+	"gov.":    true,
+	"i.e.":    true,
+	"jr.":     true,
+	"lt.":     true,
+	"mr.":     true,
+	"mrs.":    true,
+	"ms.":     true,
+	"no.":     true,
+	"prof.":   true,
+	"rep.":    true,
+	"rev.":    true,
+	"sen.":    true,
+	"sgt.":    true,
+	"sr.":     true,
+	"st.":     true,
+	"vs.":     true,
+}
+
+// isInitialism returns true for initials and dotted acronyms like "J."
+// or "U.S.", which consist of single letters followed by periods.
+func isInitialism(word string) bool {
+	runes := []rune(word)
+	if len(runes)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(runes); i += 2 {
+		if !unicode.IsLetter(runes[i]) || runes[i+1] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// sentenceTerminators end sentences in scripts that don't separate words
+// by spaces, like Chinese and Japanese, and in Arabic. They end a sentence
+// wherever they appear in a word.
+var sentenceTerminators = map[rune]bool{
+	'。': true, // ideographic full stop
+	'｡': true, // halfwidth ideographic full stop
+	'！': true, // fullwidth exclamation mark
+	'？': true, // fullwidth question mark
+	'؟': true, // Arabic question mark
+	'۔': true, // Arabic full stop
+}
+
+// countTerminators returns the number of sentences ended by runs of
+// sentenceTerminators in word.
+func countTerminators(word string) int {
+	result, prev := 0, false
+	for _, r := range word {
+		curr := sentenceTerminators[r]
+		if curr && !prev {
+			result++
+		}
+		prev = curr
+	}
+	return result
+}
+
+// isSentenceEnd returns true if word ends a sentence. Closing quotes and
+// brackets after the punctuation are ignored. Periods of abbreviations and
+// initialisms don't end sentences. Unfortunately, this also applies to
+// abbreviations that happen to appear at the end of a sentence.
+func isSentenceEnd(word string) bool {
+	word = strings.TrimRight(word, "\"')]}»”’")
+	if word == "" {
+		return false
+	}
+	switch word[len(word)-1] {
+	case '!', '?':
+		return true
+	case '.':
+		word = strings.TrimLeft(word, "\"'([{«“‘")
+		return !Abbreviations[strings.ToLower(word)] && !isInitialism(word)
+	}
+	return false
+}
+
+// firstSentences returns the text of the first n sentences of s with runs
+// of whitespace collapsed and the number of sentences it contains. Text
+// after the last sentence end counts as another sentence. If n is zero or
+// less, it returns all sentences.
+func firstSentences(s string, n int) (string, int) {
+	count, ended := 0, true
+	words := strings.Fields(s)
+	for i, word := range words {
+		prev := false
+		for j, r := range word {
+			curr := sentenceTerminators[r]
+			// Texts in Chinese and Japanese may end the n-th sentence in
+			// the middle of a word.
+			if prev && !curr && count == n {
+				return strings.Join(append(words[:i:i], word[:j]), " "), count
+			}
+			if curr && !prev {
+				count++
+			}
+			prev = curr
+		}
+		if isSentenceEnd(word) {
+			count++
+		}
+		ended = isSentenceEnd(word) || prev
+		if n > 0 && count >= n {
+			return strings.Join(words[:i+1], " "), count
+		}
+	}
+	if !ended {
+		count++
+	}
+	return strings.Join(words, " "), count
+}
+
+// asciiReplacer replaces typographic quotes, dashes and ellipses by their
+// ASCII counterparts.
+var asciiReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "‹", "'", "›", "'",
+	"“", "\"", "”", "\"", "„", "\"", "‟", "\"", "«", "\"", "»", "\"",
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "−", "-", "—", "--", "―", "--",
+	"…", "...",
+)
+
+// NormalizeText returns s with HTML entities left in the text, e.g. by double
+// escaping, decoded and typographic quotes, dashes and ellipses replaced by
+// ASCII characters. This is meant for consumers that need plain text.
+// Entities of the HTML data are already decoded when parsing.
+func NormalizeText(s string) string {
+	if strings.IndexByte(s, '&') >= 0 {
+		s = html.UnescapeString(s)
+	}
+	return asciiReplacer.Replace(s)
+}
+
+// ToValidUTF8 returns s with each run of invalid UTF-8 byte sequences
+// replaced by the replacement character U+FFFD.
+func ToValidUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
 func (t *Text) WriteText(s *Text) {
         t.WriteString(s.String())
 }
 
+// WriteString appends s to the text. Runs of whitespace, like the newlines
+// and indentation of HTML sources or non-breaking spaces, become single
+// spaces and leading and trailing whitespace is dropped. Consecutive writes
+// are separated by a space, so the words of sibling text nodes stay apart.
+// Invalid UTF-8 sequences are replaced, see ToValidUTF8.
 func (t *Text) WriteString(s string) {
+	s = ToValidUTF8(s)
+
 	// If buffer contains text, write a space first to avoid joining words
 	// accidentally.
 	needSpace := t.buffer.Len() > 0
@@ -51,20 +220,37 @@ func (t *Text) WriteString(s string) {
 			t.buffer.WriteRune(' ')
 		}
 		t.buffer.WriteString(word)
-		// Check if the current word is a "real" word.
-		if isWord(word) {
-			t.words.Add(word)
-			t.Words += 1
-		}
-		// Check if the current text part ends a sentence.
-		switch word[len(word)-1] {
-		case '!', '.', '?':
-			t.Sentences += 1
-		}
+		t.count(word)
 		needSpace = true
 	}
 }
 
+// WritePreformatted appends s to the text as it is, without collapsing its
+// whitespace, which matters for preformatted text like source code. Words
+// and sentences are counted like in WriteString.
+func (t *Text) WritePreformatted(s string) {
+	s = ToValidUTF8(s)
+	t.buffer.WriteString(s)
+	for _, word := range strings.Fields(s) {
+		t.count(word)
+	}
+}
+
+// count adds word to the word set and counts it.
+func (t *Text) count(word string) {
+	// Check if the current word is a "real" word.
+	if isWord(word) {
+		t.words.Add(word)
+		t.Words += 1
+	}
+	// Check if the current text part ends a sentence. Texts in Chinese and
+	// Japanese contain several sentences per word.
+	if isSentenceEnd(word) {
+		t.Sentences += 1
+	}
+	t.Sentences += countTerminators(word)
+}
+
 // Calculate a word-based similarity to a given text. This function returns
 // values between [0,1], where zero means the texts share no words and one
 // means the text have all words in common. This function is fuzzy.
@@ -83,6 +269,17 @@ func (t *Text) String() string {
 	return t.buffer.String()
 }
 
+// Len returns the length of the text in bytes.
 func (t *Text) Len() int {
 	return t.buffer.Len()
 }
+
+// RuneCount returns the length of the text in runes.
+func (t *Text) RuneCount() int {
+	return utf8.RuneCount(t.buffer.Bytes())
+}
+
+// LetterCount returns the number of letters in the text.
+func (t *Text) LetterCount() int {
+	return CountLetters(t.buffer.String())
+}
@@ -0,0 +1,133 @@
+package util
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTextSentences(t *testing.T) {
+	tests := []struct {
+		text      string
+		sentences int
+	}{
+		{"Hello World. How are you? Fine!", 3},
+		{"Mr. Smith met Dr. Jones in St. Louis.", 1},
+		{"The U.S. economy grew by 3.5 percent. Prices rose.", 2},
+		{"J. R. R. Tolkien wrote books.", 1},
+		{"He said \"Stop.\" Then he left (quickly.)", 2},
+		{"Wait... what", 1},
+		{"No punctuation here", 0},
+		{"今日は晴れです。明日は雨が降るでしょう。本当ですか？", 3},
+		{"「嵐が来た。」と彼は言った。", 2},
+		{"暴风雨袭击了海岸。数千户家庭停电！政府宣布进入紧急状态", 2},
+		{"هل أنت بخير؟ نعم۔", 2},
+		{"Really？！ Yes.", 2},
+	}
+	for _, test := range tests {
+		text := NewText()
+		text.WriteString(test.text)
+		if text.Sentences != test.sentences {
+			t.Errorf("%q: got %d sentences, want %d", test.text, text.Sentences, test.sentences)
+		}
+	}
+}
+
+func TestAbbreviations(t *testing.T) {
+	Abbreviations["bzw."] = true
+	defer delete(Abbreviations, "bzw.")
+
+	text := NewText()
+	text.WriteString("Rot bzw. blau.")
+	if text.Sentences != 1 {
+		t.Errorf("got %d sentences, want 1", text.Sentences)
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	tests := []struct {
+		text    string
+		bytes   int
+		runes   int
+		letters int
+	}{
+		{"Hello World", 11, 11, 10},
+		{"Crème brûlée, 2 €", 22, 17, 11},
+		{"東京 2020", 11, 7, 2},
+		{"", 0, 0, 0},
+	}
+	for _, test := range tests {
+		text := NewText()
+		text.WriteString(test.text)
+		if text.Len() != test.bytes || text.RuneCount() != test.runes || text.LetterCount() != test.letters {
+			t.Errorf("%q: got %d bytes, %d runes, %d letters", test.text, text.Len(), text.RuneCount(), text.LetterCount())
+		}
+		if n := CountLetters(test.text); n != test.letters {
+			t.Errorf("%q: CountLetters returned %d", test.text, n)
+		}
+	}
+}
+
+func TestTextWhitespace(t *testing.T) {
+	text := NewText()
+	text.WriteString("\n\t\t  Storm   hits\r\n\t\tthe\u00a0coast  \n")
+	text.WriteString("   ")
+	text.WriteString("\n\tagain\n")
+	if got := text.String(); got != "Storm hits the coast again" {
+		t.Errorf("got %q", got)
+	}
+	if text.Words != 5 {
+		t.Errorf("got %d words", text.Words)
+	}
+}
+
+func TestTextPreformatted(t *testing.T) {
+	text := NewText()
+	text.WritePreformatted("if x {\n\treturn  nil\n}")
+	if got := text.String(); got != "if x {\n\treturn  nil\n}" {
+		t.Errorf("got %q", got)
+	}
+	if text.Words != 2 {
+		t.Errorf("got %d words", text.Words)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Tom &amp; Jerry", "Tom & Jerry"},
+		{"It&#8217;s “fine” – really—they said…", "It's \"fine\" - really--they said..."},
+		{"«Bonjour» ‚Hallo‘", "\"Bonjour\" 'Hallo'"},
+		{"AT&T & co", "AT&T & co"},
+	}
+	for _, test := range tests {
+		if got := NormalizeText(test.text); got != test.want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestToValidUTF8(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Café", "Café"},
+		{"Caf\xe9 cr\xe8me", "Caf\uFFFD cr\uFFFDme"},
+		{"\xff\xfe\xfdtext", "\uFFFDtext"},
+		{"cut \xe2\x82", "cut \uFFFD"},
+	}
+	for _, test := range tests {
+		if got := ToValidUTF8(test.text); got != test.want {
+			t.Errorf("ToValidUTF8(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	text := NewText()
+	text.WriteString("Caf\xe9 au lait")
+	text.WritePreformatted(" \xff")
+	if got := text.String(); !utf8.ValidString(got) || got != "Caf\uFFFD au lait \uFFFD" {
+		t.Errorf("got text %q", got)
+	}
+}